@@ -1,7 +1,9 @@
 package ai
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/jcharra/penta-go/core"
 )
@@ -84,10 +86,11 @@ func TestFindMovesDepthOne(t *testing.T) {
 	// No matter what the actual best move is, WHITE should not be
 	// able to win immediately anymore after applying the move
 
-	b = b.SetAt(bestMoveBlack.Move.Row, bestMoveBlack.Move.Col)
+	m := bestMoveBlack.Move
+	b = b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
 	bestMoveWhite := FindBestMove(b, 5, 1)
 
-	if bestMoveWhite.value == winnerValue {
+	if winsFor(bestMoveWhite.value, core.WHITE) {
 		t.Error("White should not be able to win after Black's move, but actually was. Black moved ", bestMoveBlack)
 	}
 }
@@ -108,7 +111,282 @@ func TestFindMovesDepthTwo(t *testing.T) {
 
 	bestMoveWhite := FindBestMove(b, 5, 2)
 
-	if bestMoveWhite.value != winnerValue {
+	if !winsFor(bestMoveWhite.value, core.WHITE) {
 		t.Error("White had a forced win, but moved ", bestMoveWhite)
 	}
 }
+
+func TestShouldSwap(t *testing.T) {
+	b := core.NewBoard()
+
+	if !ShouldSwap(b.SetAt(1, 1).Rotate(core.LOWERRIGHT, core.CLOCKWISE), nil) {
+		t.Error("Should swap after a stone in the center")
+	}
+
+	corner := b.SetAt(0, 0).Rotate(core.UPPERLEFT, core.CLOCKWISE)
+	if ShouldSwap(corner, nil) {
+		t.Error("Should not swap after a stone in the corner")
+	}
+
+	// Unless the evaluation values the lines through the corner higher
+	params := DefaultEvalParams()
+	params.One = params.Center
+	if !ShouldSwap(corner, &params) {
+		t.Error("Should swap with the given evaluation parameters")
+	}
+}
+
+func TestFindBestGameMoveHandicap(t *testing.T) {
+	g := core.NewHandicapGame(core.Handicaps["rotation"])
+
+	move := FindBestGameMove(g, 3, 1).Move
+	if move.Quadrant != core.UPPERLEFT || move.Direction != core.CLOCKWISE {
+		t.Error("Move ignores the fixed rotation of the handicap: ", move.Repr())
+	}
+}
+
+func TestSearchHandicapBelowRoot(t *testing.T) {
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 0, 0, 0, 1},
+		[6]int{1, 0, 0, 0, -1, 0},
+		[6]int{0, 0, 0, 0, 0, -1},
+		[6]int{0, 1, 1, 1, 1, 0},
+		[6]int{0, 0, -1, -1, 0, 0},
+		[6]int{1, 0, -1, -1, 0, 0},
+	}
+	b.Turn = core.BLACK
+
+	// Rotations can break white's open four, but white can rotate back
+	if winner, _, ok := alphaBeta(b, Limits{Depth: 2}).Score.Win(); !ok || winner != core.WHITE {
+		t.Fatal("Expected white to win without a handicap")
+	}
+
+	// Unless white has to rotate the upper left quadrant, as after its
+	// first move with this handicap
+	g := core.NewHandicapGame(core.Handicap{Weaker: core.BLACK, FixedRotations: 3, Quadrant: core.UPPERLEFT, Direction: core.CLOCKWISE})
+	g.Board = b
+	g.Moves = make([]core.Move, 1)
+
+	best, _ := Search(context.Background(), b, Limits{Depth: 2, RootMoves: g.LegalMoves(), Game: &g})
+	if _, _, ok := best.Score.Win(); ok || len(best.PV) != 2 {
+		t.Fatal("Expected no forced win with the handicap, got ", best.Score, best.PV)
+	}
+	if m := best.PV[1]; m.Quadrant != core.UPPERLEFT || m.Direction != core.CLOCKWISE {
+		t.Error("White's reply ignores the fixed rotation: ", m.Repr())
+	}
+
+	mc := NewMCTS(1)
+	mc.Search(context.Background(), b, MCTSLimits{Iterations: 1000, RootMoves: g.LegalMoves(), Game: &g})
+	for _, child := range mc.root.children {
+		for _, grandchild := range child.children {
+			if m := grandchild.move; m.Quadrant != core.UPPERLEFT || m.Direction != core.CLOCKWISE {
+				t.Fatal("Tree contains a reply with a free rotation: ", m.Repr())
+			}
+		}
+	}
+}
+
+// minimax is a plain minimax search without any pruning, returning the
+// value from WHITE's perspective.
+func minimax(b core.Board, depth int) int {
+	if depth == 0 || b.Winner() != 0 {
+		return evaluate(b)
+	}
+
+	best := getWorstValue(b.Turn)
+	for _, m := range core.NewPosition(b).Moves() {
+		val := minimax(b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction), depth-1)
+		if better(val, best, b.Turn) {
+			best = val
+		}
+	}
+	return best
+}
+
+// Returns whether <a> is a better value than <b> from <color>'s perspective
+func better(a, b, color int) bool {
+	if color == core.WHITE {
+		return a > b
+	} else {
+		return a < b
+	}
+}
+
+func getWorstValue(color int) int {
+	if color == core.WHITE {
+		return -infinity
+	} else {
+		return infinity
+	}
+}
+
+func TestAlphaBetaMatchesMinimax(t *testing.T) {
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 1, 0, 0, -1, 0},
+		[6]int{0, 0, 1, 0, 0, 0},
+		[6]int{0, 0, 0, -1, 0, 0},
+		[6]int{0, 1, 0, 0, -1, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+	}
+
+	for _, turn := range []int{core.WHITE, core.BLACK} {
+		b.Turn = turn
+		expected := minimax(b, 2)
+		if found := AlphaBeta(b, Limits{Depth: 2}).value; found != expected {
+			t.Errorf("Alpha-beta value %v differs from minimax value %v", found, expected)
+		}
+	}
+}
+
+func TestAlphaBetaRootMoves(t *testing.T) {
+	b := core.NewBoard()
+	root := []core.Move{{Row: 5, Col: 5, Quadrant: core.UPPERLEFT, Direction: core.CLOCKWISE}}
+
+	if m := AlphaBeta(b, Limits{Depth: 2, RootMoves: root}).Move; m != root[0] {
+		t.Error("Expected the only root move, got ", m.Repr())
+	}
+}
+
+func TestPreferFastestWin(t *testing.T) {
+	b := core.NewBoard()
+
+	// White wins immediately by completing the top row, but could also
+	// take its time, as black has no threats.
+	b.Fields = [6][6]int{
+		[6]int{1, 1, 1, 1, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, -1, 0, 0, -1, 0},
+		[6]int{-1, 0, 0, 0, 0, -1},
+	}
+	b.Turn = core.WHITE
+
+	best := AlphaBeta(b, Limits{Depth: 3})
+	if best.value != winnerValue-1 {
+		t.Errorf("Expected a win in one ply, got value %v for %v", best.value, best.Move.Repr())
+	}
+
+	m := best.Move
+	if b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction).Winner() != core.WHITE {
+		t.Error("Expected the immediately winning move, got ", m.Repr())
+	}
+}
+
+func TestDecidedPositionsAreNotSearched(t *testing.T) {
+	b := core.NewBoard()
+
+	// Black has already won, so white's apparent win is irrelevant
+	b.Fields = [6][6]int{
+		[6]int{1, 1, 1, 1, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{-1, -1, -1, -1, -1, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+	}
+	b.Turn = core.WHITE
+
+	s := newSearcher(context.Background(), b, Limits{})
+	if val := s.negamax(2, -infinity, infinity); val != -winnerValue {
+		t.Error("Expected the value of a lost position, got ", val)
+	}
+	if s.nodes != 1 {
+		t.Error("Expected no search below a decided position, searched nodes: ", s.nodes)
+	}
+}
+
+func TestSearchTimeBudget(t *testing.T) {
+	b := core.NewBoard().SetAt(1, 1).Rotate(core.LOWERRIGHT, core.CLOCKWISE)
+
+	start := time.Now()
+	best, err := Search(context.Background(), b, Limits{Time: 200 * time.Millisecond})
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Error(err)
+	}
+	if elapsed > time.Second {
+		t.Error("Search exceeded its time budget by far: ", elapsed)
+	}
+	if b.Fields[best.Move.Row][best.Move.Col] != 0 {
+		t.Error("Search returned an illegal move: ", best.Move.Repr())
+	}
+}
+
+// openThreeBoard returns a board on which white, to move, has three stones
+// in the middle of row 1. Extending them to an open four wins in three
+// plies.
+func openThreeBoard() core.Board {
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 1, 1, 1, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, -1, 0, 0, -1, 0},
+		[6]int{0, 0, -1, 0, 0, 0},
+	}
+	b.Turn = core.WHITE
+	return b
+}
+
+func TestSearchDepthLimit(t *testing.T) {
+	b := openThreeBoard()
+
+	// Same forced win as in TestFindMovesDepthTwo
+	var infos []Info
+	best, _ := Search(context.Background(), b, Limits{Depth: 3, Progress: func(info Info) { infos = append(infos, info) }})
+	if !winsFor(int(best.Score), core.WHITE) {
+		t.Error("White had a forced win, but moved ", best.Move.Repr())
+	}
+
+	if len(infos) != 3 || infos[2].Depth != 3 || infos[2].Score != best.Score {
+		t.Fatal("Expected progress for every iteration: ", infos)
+	}
+
+	// Both the final move and the win within three plies are in the PV
+	pv := infos[2].PV
+	if len(pv) != 3 || pv[0] != best.Move {
+		t.Fatal("Unexpected principal variation: ", pv)
+	}
+	for _, m := range pv {
+		b = b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+	}
+	if b.Winner() != core.WHITE {
+		t.Error("Expected white to win at the end of the principal variation:\n", b.Repr())
+	}
+
+	// The result describes the last iteration
+	if best.Depth != 3 || len(best.PV) != 3 || best.Nodes < infos[2].Nodes || best.Elapsed < infos[2].Elapsed {
+		t.Errorf("Result differs from the last iteration: %+v", best)
+	}
+}
+
+func TestSearchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Search(ctx, core.NewBoard(), Limits{}); err != context.Canceled {
+		t.Error("Expected search to be canceled, got ", err)
+	}
+}
+
+func TestSearchParallel(t *testing.T) {
+	b := openThreeBoard()
+
+	best, err := Search(context.Background(), b, Limits{Depth: 3, Threads: 4})
+	if err != nil || !winsFor(int(best.Score), core.WHITE) {
+		t.Error("White had a forced win, but moved ", best.Move.Repr())
+	}
+
+	// Helpers stop with the main search
+	start := time.Now()
+	Search(context.Background(), core.NewBoard(), Limits{Time: 100 * time.Millisecond, Threads: 4})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Error("Parallel search exceeded its time budget: ", elapsed)
+	}
+}
@@ -0,0 +1,123 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// Line is one of the alternatives found by Analyze.
+type Line struct {
+	Move  core.Move
+	Score Score
+	// Principal variation, starting with Move
+	PV []core.Move
+}
+
+// Score returns the value of the move from WHITE's perspective.
+func (em EvaluatedMove) Score() Score {
+	return Score(em.value)
+}
+
+// Analyze searches like Search, but finds the <k> best moves instead of
+// only one, each with its own score and principal variation. Moves leading
+// to the same board up to symmetry of the whole board count only once. The
+// lines are ordered best first, for the player to move. It returns an error
+// if k is less than one.
+//
+// Analyze searches each of the k moves separately, so it gets less deep
+// than Search in the same time. It uses a single goroutine and ignores the
+// opening book.
+func Analyze(ctx context.Context, b core.Board, limits Limits, k int) ([]Line, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("invalid number of lines %v", k)
+	}
+	if limits.Table == nil {
+		limits.Table = NewTranspositionTable(defaultTableSize)
+	}
+	s := newSearcher(ctx, b, limits)
+	if b.Winner() != 0 {
+		return nil, nil
+	}
+
+	moves := limits.RootMoves
+	if len(moves) == 0 {
+		moves = s.moves()
+	}
+	moves = distinctMoves(b, moves)
+
+	start := time.Now()
+	var lines []Line
+	for depth := 1; depth <= s.maxDepth() || depth == 1; depth++ {
+		if ctx.Err() != nil {
+			break
+		}
+		if depth > 1 && limits.Time > 0 {
+			s.deadline = start.Add(limits.Time)
+			if time.Now().After(s.deadline) {
+				break
+			}
+		}
+
+		found := s.searchLines(moves, depth, k)
+		if s.stopped {
+			break
+		}
+
+		lines = found
+		if limits.Progress != nil {
+			info := s.info(depth, lines[0].Score, time.Since(start))
+			info.PV = lines[0].PV
+			limits.Progress(info)
+		}
+	}
+	return lines, ctx.Err()
+}
+
+// searchLines searches the moves to the given depth k times, each time
+// without the moves found before, and returns the lines best first.
+func (s *searcher) searchLines(moves []core.Move, depth, k int) []Line {
+	rootMoves := s.limits.RootMoves
+	defer func() { s.limits.RootMoves = rootMoves }()
+
+	b := s.pos.Board()
+	sign := Score(colorSign(b.Turn))
+	remaining := append([]core.Move(nil), moves...)
+
+	var lines []Line
+	for len(lines) < k && len(remaining) > 0 {
+		// The value of a subset of the root moves must not be stored as the
+		// value of the position
+		s.limits.RootMoves = remaining
+		move, val := s.searchRoot(remaining, depth)
+		if s.stopped {
+			return nil
+		}
+
+		pv := make([]core.Move, len(s.pv[0]))
+		copy(pv, s.pv[0])
+		lines = append(lines, Line{Move: move, Score: sign * Score(val), PV: pv})
+		remaining = removeEquivalent(b, remaining, move)
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return sign*lines[i].Score > sign*lines[j].Score
+	})
+	return lines
+}
+
+// removeEquivalent removes the moves leading to the same board as m, up to
+// symmetry of the whole board.
+func removeEquivalent(b core.Board, moves []core.Move, m core.Move) []core.Move {
+	target := b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction).Canonical()
+	kept := moves[:0]
+	for _, other := range moves {
+		if b.SetAt(other.Row, other.Col).Rotate(other.Quadrant, other.Direction).Canonical() != target {
+			kept = append(kept, other)
+		}
+	}
+	return kept
+}
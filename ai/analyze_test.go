@@ -0,0 +1,54 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+func TestAnalyze(t *testing.T) {
+	b := openThreeBoard()
+
+	var infos []Info
+	lines, err := Analyze(context.Background(), b, Limits{Depth: 2, Progress: func(info Info) { infos = append(infos, info) }}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 || len(infos) != 2 {
+		t.Fatal("Expected three lines and progress for two iterations: ", lines, infos)
+	}
+
+	seen := make(map[core.Board]bool)
+	for i, l := range lines {
+		if i > 0 && l.Score > lines[i-1].Score {
+			t.Error("Lines are not ordered best first: ", lines)
+		}
+		// Transposition table hits may cut the variation short
+		if len(l.PV) == 0 || len(l.PV) > 2 || l.PV[0] != l.Move {
+			t.Error("Unexpected principal variation ", l.PV)
+		}
+
+		after := b.SetAt(l.Move.Row, l.Move.Col).Rotate(l.Move.Quadrant, l.Move.Direction).Canonical()
+		if seen[after] {
+			t.Error("Equivalent moves in different lines: ", l.Move.Repr())
+		}
+		seen[after] = true
+
+		// Each line's score is the one of searching its move alone
+		alone := AlphaBeta(b, Limits{Depth: 2, RootMoves: []core.Move{l.Move}})
+		if alone.Score() != l.Score {
+			t.Errorf("Score %v of %v differs from %v", l.Score, l.Move.Repr(), alone.Score())
+		}
+	}
+
+	// The best line is the one of Search
+	best, _ := Search(context.Background(), b, Limits{Depth: 2})
+	if best.Score != lines[0].Score {
+		t.Error("Best line differs from Search: ", best.Score, lines[0].Score)
+	}
+
+	if _, err := Analyze(context.Background(), b, Limits{Depth: 1, Progress: func(Info) {}}, 0); err == nil {
+		t.Error("Expected an error for no lines")
+	}
+}
@@ -0,0 +1,277 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// BookMove is a move of an opening book. Moves with a higher weight are
+// chosen more often.
+type BookMove struct {
+	Move   core.Move
+	Weight int
+}
+
+// Book is an opening book, i.e. a collection of good moves for positions
+// early in the game. Positions are keyed by their canonical hash, so that an
+// entry applies to all rotations and mirror images of its board.
+type Book struct {
+	// Moves of each canonical position, in the orientation of the canonical
+	// hash, see core.Board.CanonicalHash
+	entries map[uint64][]BookMove
+	rnd     *rand.Rand
+}
+
+// NewBook returns an empty book, which picks among the moves of a position
+// at random.
+func NewBook() *Book {
+	return &Book{entries: make(map[uint64][]BookMove), rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Len returns the number of positions in the book.
+func (bk *Book) Len() int {
+	return len(bk.entries)
+}
+
+// Add adds the weight to the move of the board.
+func (bk *Book) Add(b core.Board, m core.Move, weight int) {
+	key, symmetry := b.CanonicalHash()
+	bk.add(key, m.Transformed(symmetry), weight)
+}
+
+func (bk *Book) add(key uint64, m core.Move, weight int) {
+	moves := bk.entries[key]
+	for i := range moves {
+		if moves[i].Move == m {
+			moves[i].Weight += weight
+			return
+		}
+	}
+	bk.entries[key] = append(moves, BookMove{Move: m, Weight: weight})
+}
+
+// Moves returns the book moves of the board.
+func (bk *Book) Moves(b core.Board) []BookMove {
+	key, symmetry := b.CanonicalHash()
+	moves := make([]BookMove, len(bk.entries[key]))
+	for i, bm := range bk.entries[key] {
+		moves[i] = BookMove{Move: bm.Move.Transformed(core.InverseSymmetry(symmetry)), Weight: bm.Weight}
+	}
+	return moves
+}
+
+// Probe picks one of the book moves of the board at random, according to
+// their weights. If <allowed> is not empty, only moves among them are
+// picked, e.g. the legal moves of a handicap game.
+func (bk *Book) Probe(b core.Board, allowed []core.Move) (core.Move, bool) {
+	var candidates []BookMove
+	total := 0
+	for _, bm := range bk.Moves(b) {
+		if bm.Weight > 0 && (len(allowed) == 0 || containsMove(allowed, bm.Move)) {
+			candidates = append(candidates, bm)
+			total += bm.Weight
+		}
+	}
+	if total == 0 {
+		return core.Move{}, false
+	}
+
+	r := bk.rnd.Intn(total)
+	for _, bm := range candidates {
+		if r < bm.Weight {
+			return bm.Move, true
+		}
+		r -= bm.Weight
+	}
+	panic("weights out of sync")
+}
+
+func containsMove(moves []core.Move, m core.Move) bool {
+	for _, other := range moves {
+		if other == m {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadBook reads a book in the format written by Save.
+func LoadBook(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadBook(f)
+}
+
+// ReadBook reads a book in the format written by Write. Empty lines and
+// lines starting with '#' are ignored.
+func ReadBook(r io.Reader) (*Book, error) {
+	bk := NewBook()
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("line %v: expected key, move and weight", lineNo)
+		}
+		key, err := strconv.ParseUint(fields[0], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: invalid key %q", lineNo, fields[0])
+		}
+		m, err := core.ParseMove(strings.Join(fields[1:4], " "))
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", lineNo, err)
+		}
+		weight, err := strconv.Atoi(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: invalid weight %q", lineNo, fields[4])
+		}
+		bk.add(key, m, weight)
+	}
+	return bk, scanner.Err()
+}
+
+// Save writes the book to a file, see Write.
+func (bk *Book) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := bk.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes one line per book move: the canonical hash of the position
+// in hex, the move in the orientation of that hash and its weight.
+func (bk *Book) Write(w io.Writer) error {
+	keys := make([]uint64, 0, len(bk.entries))
+	for key := range bk.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# canonical position, move, weight")
+	for _, key := range keys {
+		for _, bm := range bk.entries[key] {
+			fmt.Fprintf(bw, "%016x %v %v\n", key, bm.Move.Repr(), bm.Weight)
+		}
+	}
+	return bw.Flush()
+}
+
+// BookFromRecords builds a book from the first <plies> moves of the
+// finished games. A move scores 2 for each won game it was played in and 1
+// for each drawn one.
+func BookFromRecords(games []core.Game, plies int) *Book {
+	bk := NewBook()
+	for _, g := range games {
+		result := g.Result()
+		if result == 0 {
+			continue
+		}
+
+		b := core.NewBoard()
+		if !g.Handicap.IsZero() {
+			b = g.Handicap.Board()
+		}
+		for i, m := range g.Moves {
+			if i >= plies {
+				break
+			}
+			switch result {
+			case b.Turn:
+				bk.Add(b, m, 2)
+			case core.DRAW:
+				bk.Add(b, m, 1)
+			}
+			b = b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+		}
+	}
+	return bk
+}
+
+// BookOptions control BookFromSearch.
+type BookOptions struct {
+	// Number of plies from the empty board the book covers
+	Plies int
+	// Maximum number of moves per position
+	Width int
+	// Moves valued at most this much below the best one are added as
+	// alternatives.
+	Margin int
+	// Limits of the analysis of each position
+	Limits Limits
+}
+
+// BookFromSearch builds a book by searching the positions of the first
+// plies. In each position, it looks for up to <Width> good moves with
+// Analyze and continues with all of them. Better moves get higher weights.
+func BookFromSearch(ctx context.Context, opts BookOptions) (*Book, error) {
+	bk := NewBook()
+	positions := []core.Board{core.NewBoard()}
+	seen := make(map[uint64]bool)
+
+	for ply := 0; ply < opts.Plies; ply++ {
+		var next []core.Board
+		for _, b := range positions {
+			key, _ := b.CanonicalHash()
+			if seen[key] || b.Winner() != 0 {
+				continue
+			}
+			seen[key] = true
+
+			moves, err := bookCandidates(ctx, b, opts)
+			if err != nil {
+				return bk, err
+			}
+			for i, m := range moves {
+				bk.Add(b, m, len(moves)-i)
+				next = append(next, b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction))
+			}
+		}
+		positions = next
+	}
+	return bk, nil
+}
+
+// bookCandidates analyzes the board and returns the moves within the
+// margin of the best one, best first.
+func bookCandidates(ctx context.Context, b core.Board, opts BookOptions) ([]core.Move, error) {
+	lines, err := Analyze(ctx, b, opts.Limits, opts.Width)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []core.Move
+	sign := Score(colorSign(b.Turn))
+	for _, l := range lines {
+		if sign*l.Score < sign*lines[0].Score-Score(opts.Margin) {
+			break
+		}
+		found = append(found, l.Move)
+	}
+	return found, nil
+}
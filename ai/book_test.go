@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+func TestBookProbe(t *testing.T) {
+	b := core.NewBoard().SetAt(0, 1).Rotate(core.LOWERLEFT, core.CLOCKWISE)
+	m := core.Move{Row: 1, Col: 1, Quadrant: core.UPPERRIGHT, Direction: core.COUNTERCLOCKWISE}
+
+	bk := NewBook()
+	bk.Add(b, m, 3)
+
+	// The entry applies to all rotations and mirror images of the board
+	for symmetry := 0; symmetry < 8; symmetry++ {
+		transformed := b
+		if symmetry >= 4 {
+			transformed = mirror(b)
+		}
+		transformed = rotate(transformed, symmetry%4)
+
+		probed, ok := bk.Probe(transformed, nil)
+		if !ok || probed != m.Transformed(symmetry) {
+			t.Errorf("Unexpected book move %v for symmetry %v", probed.Repr(), symmetry)
+		}
+	}
+
+	if _, ok := bk.Probe(b, []core.Move{{Row: 2, Col: 2}}); ok {
+		t.Error("Book moves should be restricted to the allowed moves")
+	}
+	if _, ok := bk.Probe(core.NewBoard(), nil); ok {
+		t.Error("Expected no book move for an unknown position")
+	}
+
+	// Moves without weight are never picked
+	other := core.Move{Row: 5, Col: 5}
+	bk.Add(b, other, 0)
+	for i := 0; i < 20; i++ {
+		if probed, _ := bk.Probe(b, nil); probed != m {
+			t.Fatal("Picked a move without weight")
+		}
+	}
+
+	best, err := Search(context.Background(), b, Limits{Depth: 3, Book: bk})
+	if err != nil || best.Move != m {
+		t.Error("Search should play the book move, got ", best.Move.Repr())
+	}
+}
+
+func TestBookReadWrite(t *testing.T) {
+	bk := NewBook()
+	b := core.NewBoard()
+	bk.Add(b, core.Move{Row: 1, Col: 1}, 2)
+	bk.Add(b, core.Move{Row: 4, Col: 1, Quadrant: core.LOWERRIGHT, Direction: core.COUNTERCLOCKWISE}, 1)
+	bk.Add(b.SetAt(1, 1), core.Move{Row: 4, Col: 4}, 5)
+
+	var buf bytes.Buffer
+	if err := bk.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBook(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Len() != 2 || len(read.Moves(b)) != 2 || read.Moves(b.SetAt(1, 1))[0].Weight != 5 {
+		t.Error("Book did not survive a round trip")
+	}
+
+	if _, err := ReadBook(strings.NewReader("123 (1|1) Q0 R0\n")); err == nil {
+		t.Error("Expected an error for a line without weight")
+	}
+}
+
+func TestBookFromRecords(t *testing.T) {
+	// White wins along the top row
+	records := `(0|0) Q3 R0
+(5|0) Q3 R0
+(0|1) Q3 R0
+(5|1) Q3 R0
+(0|2) Q3 R0
+(5|2) Q3 R0
+(0|3) Q3 R0
+(4|5) Q3 R0
+(0|4) Q2 R0
+`
+	games, err := core.ReadRecords(strings.NewReader(records))
+	if err != nil || games[0].Result() != core.WHITE {
+		t.Fatal("Expected a game won by white: ", err)
+	}
+
+	bk := BookFromRecords(games, 4)
+	if bk.Len() != 2 {
+		t.Error("Expected the winner's first two moves in the book, found positions: ", bk.Len())
+	}
+	if moves := bk.Moves(core.NewBoard()); len(moves) != 1 || moves[0].Weight != 2 {
+		t.Error("Unexpected book moves for the empty board: ", moves)
+	}
+}
+
+func TestBookFromSearch(t *testing.T) {
+	bk, err := BookFromSearch(context.Background(), BookOptions{Plies: 2, Width: 2, Margin: 1000, Limits: Limits{Depth: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two moves for the empty board and both replies to each of them
+	moves := bk.Moves(core.NewBoard())
+	if len(moves) != 2 || moves[0].Weight <= moves[1].Weight || bk.Len() != 3 {
+		t.Error("Unexpected book: ", moves, bk.Len())
+	}
+}
@@ -0,0 +1,135 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// Engine chooses moves. Engines may keep state between moves, e.g. a
+// search tree, so each game should use its own engine.
+type Engine interface {
+	// BestMove returns the move the engine plays on the board. If the
+	// context is done before the engine is finished, it returns the
+	// context's error, along with the best move found so far, if any.
+	BestMove(ctx context.Context, b core.Board, limits Limits) (Result, error)
+}
+
+// EngineNames are the names accepted by NewEngine, the default one first.
+var EngineNames = []string{"alphabeta", "beam", "mcts", "greedy", "random"}
+
+// NewEngine returns a new engine of the given name, see EngineNames.
+func NewEngine(name string) (Engine, error) {
+	seed := time.Now().UnixNano()
+	switch name {
+	case "alphabeta":
+		return AlphaBetaEngine{}, nil
+	case "beam":
+		return BeamEngine{Breadth: 5, Depth: 3}, nil
+	case "mcts":
+		return &MCTSEngine{MCTS: NewMCTS(seed)}, nil
+	case "greedy":
+		return GreedyEngine{}, nil
+	case "random":
+		return &RandomEngine{rnd: rand.New(rand.NewSource(seed))}, nil
+	}
+	return nil, fmt.Errorf("unknown engine %q, choose one of %s", name, strings.Join(EngineNames, ", "))
+}
+
+// bookMove probes the opening book of the limits, if any.
+func bookMove(b core.Board, limits Limits) (Result, bool) {
+	if limits.Book == nil {
+		return Result{}, false
+	}
+	m, ok := limits.Book.Probe(b, limits.RootMoves)
+	return Result{Move: m}, ok
+}
+
+// AlphaBetaEngine plays the result of Search.
+type AlphaBetaEngine struct{}
+
+func (AlphaBetaEngine) BestMove(ctx context.Context, b core.Board, limits Limits) (Result, error) {
+	return Search(ctx, b, limits)
+}
+
+// BeamEngine searches to a fixed depth, following only the <Breadth>
+// statically best moves at each node, like FindBestMove. It ignores the
+// time and depth limits.
+type BeamEngine struct {
+	Breadth int
+	Depth   int
+}
+
+func (e BeamEngine) BestMove(ctx context.Context, b core.Board, limits Limits) (Result, error) {
+	if res, ok := bookMove(b, limits); ok {
+		return res, nil
+	}
+	return alphaBeta(b, Limits{Depth: e.Depth + 1, Beam: e.Breadth, RootMoves: limits.RootMoves, Game: limits.Game, Eval: limits.Eval}), ctx.Err()
+}
+
+// MCTSEngine plays the result of a Monte Carlo tree search. It reuses its
+// tree from one move to the next. Without a time limit, it runs
+// <Iterations> playouts. Its results count the playouts of the move as
+// nodes and follow the most visited moves as principal variation.
+type MCTSEngine struct {
+	*MCTS
+	Iterations  int
+	Exploration float64
+	Guided      bool
+}
+
+// Number of playouts of MCTSEngine if neither iterations nor time are given
+const defaultIterations = 10000
+
+func (e *MCTSEngine) BestMove(ctx context.Context, b core.Board, limits Limits) (Result, error) {
+	if res, ok := bookMove(b, limits); ok {
+		return res, nil
+	}
+	mcLimits := MCTSLimits{
+		Iterations:  e.Iterations,
+		Time:        limits.Time,
+		Exploration: e.Exploration,
+		Guided:      e.Guided,
+		Eval:        limits.Eval,
+		RootMoves:   limits.RootMoves,
+		Game:        limits.Game,
+	}
+	if mcLimits.Iterations == 0 && mcLimits.Time == 0 {
+		mcLimits.Iterations = defaultIterations
+	}
+
+	start := time.Now()
+	m, err := e.Search(ctx, b, mcLimits)
+	return Result{Move: m, PV: e.principalVariation(), Nodes: int64(e.playouts), Elapsed: time.Since(start)}, err
+}
+
+// GreedyEngine plays the move with the best static evaluation, or an
+// immediately winning one.
+type GreedyEngine struct{}
+
+func (GreedyEngine) BestMove(ctx context.Context, b core.Board, limits Limits) (Result, error) {
+	if res, ok := bookMove(b, limits); ok {
+		return res, nil
+	}
+	return alphaBeta(b, Limits{Depth: 1, RootMoves: limits.RootMoves, Game: limits.Game, Eval: limits.Eval}), ctx.Err()
+}
+
+// RandomEngine plays uniformly random moves. It ignores the opening book.
+type RandomEngine struct {
+	rnd *rand.Rand
+}
+
+func (e *RandomEngine) BestMove(ctx context.Context, b core.Board, limits Limits) (Result, error) {
+	moves := limits.RootMoves
+	if len(moves) == 0 {
+		moves = core.NewPosition(b).Moves()
+	}
+	if len(moves) == 0 {
+		return Result{}, ctx.Err()
+	}
+	return Result{Move: moves[e.rnd.Intn(len(moves))]}, ctx.Err()
+}
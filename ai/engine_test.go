@@ -0,0 +1,102 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+func TestEngines(t *testing.T) {
+	b := openFourBoard()
+
+	for _, name := range EngineNames {
+		e, err := NewEngine(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		limits := Limits{Time: 200 * time.Millisecond}
+		if mc, ok := e.(*MCTSEngine); ok {
+			// The time may not suffice for enough playouts, e.g. with the
+			// race detector
+			mc.MCTS, mc.Iterations = NewMCTS(1), 3000
+			limits.Time = 0
+		}
+
+		res, err := e.BestMove(context.Background(), b, limits)
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", name, err)
+		}
+		m := res.Move
+		if b.Fields[m.Row][m.Col] != 0 {
+			t.Errorf("%v: illegal move %v", name, m.Repr())
+		}
+
+		// Every engine but the random one finds the immediate win
+		if after := b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction); name != "random" && after.Winner() != core.WHITE {
+			t.Errorf("%v: white could win immediately, but moved %v", name, m.Repr())
+		}
+		if name != "random" && (len(res.PV) == 0 || res.PV[0] != m || res.Nodes == 0) {
+			t.Errorf("%v: expected a principal variation starting with the move, got %+v", name, res)
+		}
+
+		rootMoves := []core.Move{{Row: 0, Col: 0, Quadrant: core.LOWERRIGHT, Direction: core.CLOCKWISE}}
+		res, _ = e.BestMove(context.Background(), b, Limits{Time: limits.Time, RootMoves: rootMoves})
+		if res.Move != rootMoves[0] {
+			t.Errorf("%v: expected the only root move, got %v", name, res.Move.Repr())
+		}
+	}
+
+	if _, err := NewEngine("oracle"); err == nil {
+		t.Error("Expected an error for an unknown engine")
+	}
+}
+
+func TestMCTSEngineNodes(t *testing.T) {
+	e := &MCTSEngine{MCTS: NewMCTS(1), Iterations: 100}
+	e.BestMove(context.Background(), core.NewBoard(), Limits{})
+
+	// The second search reuses the tree, but only its own playouts count
+	res, _ := e.BestMove(context.Background(), core.NewBoard(), Limits{})
+	if res.Nodes != 100 || e.root.visits != 200 {
+		t.Errorf("Expected 100 nodes of 200 visits, got %v of %v", res.Nodes, e.root.visits)
+	}
+}
+
+func TestMCTSEngineReusesTree(t *testing.T) {
+	g := core.NewGame(false)
+	// Little exploration lets the principal variation grow deep quickly
+	e := &MCTSEngine{MCTS: NewMCTS(1), Iterations: 2000, Exploration: 0.1}
+	res, _ := e.BestMove(context.Background(), g.Board, Limits{RootMoves: g.LegalMoves(), Game: &g})
+	if len(res.PV) < 2 {
+		t.Fatal("Expected a reply in the principal variation: ", res.PV)
+	}
+
+	// Reply with the move the search expects, as the front ends do
+	var reply *mctsNode
+	for _, child := range e.root.children {
+		if child.move == res.PV[0] {
+			for _, grandchild := range child.children {
+				if grandchild.move == res.PV[1] {
+					reply = grandchild
+				}
+			}
+		}
+	}
+	visits := reply.visits
+	if visits < 2 {
+		t.Fatal("Expected the reply to be searched, found visits: ", visits)
+	}
+	if err := g.Play(res.PV[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Play(res.PV[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	e.BestMove(context.Background(), g.Board, Limits{RootMoves: g.LegalMoves(), Game: &g})
+	if e.root != reply || e.root.visits != visits+2000 {
+		t.Errorf("Expected the subtree with %v visits to be reused, found %v visits", visits, e.root.visits)
+	}
+}
@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"math/bits"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// evaluate returns the static value of the board from WHITE's perspective,
+// using the default parameters.
+func evaluate(b core.Board) int {
+	return defaultEvalParams.evaluate(b)
+}
+
+// evaluate returns the static value of the board from WHITE's perspective.
+//
+// It scores all 32 windows of five fields in rows, columns and diagonals,
+// see core.Windows. Each window counts for the color that can still
+// complete it, according to the number of stones it already holds there, or
+// would hold after rotating one quadrant. The evaluation is symmetric:
+// swapping the colors of all stones negates it.
+func (p *EvalParams) evaluate(b core.Board) int {
+	winner := b.Winner()
+	if winner == core.WHITE {
+		return winnerValue
+	} else if winner == core.BLACK {
+		return -winnerValue
+	} else if winner == core.DRAW {
+		return 0
+	}
+
+	val := 0
+
+	for _, col := range []int{b.Fields[1][1], b.Fields[1][4], b.Fields[4][1], b.Fields[4][4]} {
+		val += p.Center * colorSign(col)
+	}
+
+	white, black := bitboards(&b)
+	var rotWhite, rotBlack [8]uint64
+	for r := range rotWhite {
+		rotWhite[r] = rotateBits(white, r)
+		rotBlack[r] = rotateBits(black, r)
+	}
+
+	for _, w := range windowMasks {
+		bestWhite, bestBlack := 0, 0
+		for r := range rotWhite {
+			rw, rb := bits.OnesCount64(rotWhite[r]&w), bits.OnesCount64(rotBlack[r]&w)
+			if rb == 0 && rw > bestWhite {
+				bestWhite = rw
+			}
+			if rw == 0 && rb > bestBlack {
+				bestBlack = rb
+			}
+		}
+
+		ownWhite, ownBlack := bits.OnesCount64(white&w), bits.OnesCount64(black&w)
+		val += p.lineValue(ownWhite, ownBlack, bestWhite) - p.lineValue(ownBlack, ownWhite, bestBlack)
+	}
+
+	return val
+}
+
+// lineValue returns the value of a window holding <own> and <opposing>
+// stones for the owner of <own>, if one rotation can bring at most
+// <rotated> own stones into the window without opposing ones.
+func (p *EvalParams) lineValue(own, opposing, rotated int) int {
+	lineValues := [6]int{0, p.One, p.Two, p.Three, p.Four, p.RotatedFive}
+	val := 0
+	if opposing == 0 {
+		val = lineValues[own]
+	}
+	if lineValues[rotated] > val {
+		val += (lineValues[rotated] - val) * p.RotationPercent / 100
+	}
+	return val
+}
+
+// bitboards returns the stones of both colors as bit sets, using bit
+// 6*row+col for the field row|col.
+func bitboards(b *core.Board) (white, black uint64) {
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			switch b.Fields[i][j] {
+			case core.WHITE:
+				white |= 1 << uint(6*i+j)
+			case core.BLACK:
+				black |= 1 << uint(6*i+j)
+			}
+		}
+	}
+	return white, black
+}
+
+// Bit sets of the fields of each window in core.Windows
+var windowMasks = makeWindowMasks()
+
+func makeWindowMasks() []uint64 {
+	masks := make([]uint64, len(core.Windows))
+	for k, w := range core.Windows {
+		for _, c := range w {
+			masks[k] |= 1 << uint(6*c.Row+c.Col)
+		}
+	}
+	return masks
+}
+
+// rotationMoves[r] lists the source and target bits of the nine fields
+// moved by rotation r, i.e. quadrant r/2 in direction r%2.
+var rotationMoves = makeRotationMoves()
+
+func makeRotationMoves() [8][9][2]uint {
+	var moves [8][9][2]uint
+
+	// Let a board of field indexes show where each field goes
+	var indexes core.Board
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			indexes.Fields[i][j] = 6*i + j
+		}
+	}
+
+	for r := range moves {
+		quadrant := r / 2
+		rotated := indexes.Rotate(quadrant, r%2)
+		offY, offX := 3*(quadrant/2), 3*(quadrant%2)
+		k := 0
+		for i := offY; i < offY+3; i++ {
+			for j := offX; j < offX+3; j++ {
+				moves[r][k] = [2]uint{uint(rotated.Fields[i][j]), uint(6*i + j)}
+				k++
+			}
+		}
+	}
+	return moves
+}
+
+// rotateBits applies rotation r (see rotationMoves) to a bit set.
+func rotateBits(set uint64, r int) uint64 {
+	rotated := set
+	for _, m := range rotationMoves[r] {
+		rotated &^= 1 << m[1]
+	}
+	for _, m := range rotationMoves[r] {
+		rotated |= (set >> m[0] & 1) << m[1]
+	}
+	return rotated
+}
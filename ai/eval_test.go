@@ -0,0 +1,98 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+func TestEvaluateSymmetric(t *testing.T) {
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 1, 0, 0, 0, -1},
+		[6]int{0, 1, 1, 0, -1, 0},
+		[6]int{0, 0, -1, 0, 0, 0},
+		[6]int{0, 1, 0, 0, 0, 0},
+		[6]int{-1, 0, 0, 1, -1, 0},
+		[6]int{0, 0, -1, 0, 0, 0},
+	}
+
+	swapped := b
+	for i := range swapped.Fields {
+		for j := range swapped.Fields[i] {
+			swapped.Fields[i][j] = -swapped.Fields[i][j]
+		}
+	}
+	if evaluate(b) != -evaluate(swapped) {
+		t.Error("Swapping colors should negate the evaluation: ", evaluate(b), evaluate(swapped))
+	}
+
+	if evaluate(b) != evaluate(b.Canonical()) {
+		t.Error("Rotating the whole board should not change the evaluation")
+	}
+
+	if evaluate(core.NewBoard()) != 0 {
+		t.Error("Empty board should be even")
+	}
+}
+
+func TestEvaluateDiagonals(t *testing.T) {
+	diagonal := core.NewBoard()
+	diagonal.Fields[1][1] = core.WHITE
+	diagonal.Fields[2][2] = core.WHITE
+	diagonal.Fields[3][3] = core.WHITE
+
+	scattered := core.NewBoard()
+	scattered.Fields[1][1] = core.WHITE
+	scattered.Fields[0][5] = core.WHITE
+	scattered.Fields[5][3] = core.WHITE
+
+	if evaluate(diagonal) <= evaluate(scattered) {
+		t.Error("Three on a diagonal should be worth more than scattered stones")
+	}
+}
+
+func TestEvaluateBlockedWindows(t *testing.T) {
+	open := core.NewBoard()
+	open.Fields[0][0] = core.WHITE
+	open.Fields[0][1] = core.WHITE
+	open.Fields[0][2] = core.WHITE
+
+	blocked := open
+	blocked.Fields[0][3] = core.BLACK
+	blocked.Fields[0][4] = core.BLACK
+
+	// The black stones also block the other row window
+	if evaluate(blocked) >= evaluate(open)-defaultEvalParams.Three {
+		t.Error("Blocked windows should not count as lines")
+	}
+}
+
+func TestEvaluateRotationThreats(t *testing.T) {
+	// Rotating the upper left quadrant clockwise moves (2,0) to (0,0),
+	// giving three stones in the top row
+	threat := core.NewBoard()
+	threat.Fields[2][0] = core.WHITE
+	threat.Fields[0][3] = core.WHITE
+	threat.Fields[0][4] = core.WHITE
+
+	// A stone in the lower left quadrant can't be rotated into the top row
+	noThreat := core.NewBoard()
+	noThreat.Fields[5][0] = core.WHITE
+	noThreat.Fields[0][3] = core.WHITE
+	noThreat.Fields[0][4] = core.WHITE
+
+	if evaluate(threat) <= evaluate(noThreat) {
+		t.Error("Stones one rotation away from a line should count")
+	}
+}
+
+func TestEvaluateIgnoresEmptyRuns(t *testing.T) {
+	b := core.NewBoard()
+	b.Fields[0][0] = core.BLACK
+	b.Fields[5][5] = core.WHITE
+
+	if evaluate(b) != 0 {
+		t.Error("Empty fields should not count for either color: ", evaluate(b))
+	}
+}
@@ -0,0 +1,359 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// Exploration constant of UCT, if none is given
+const defaultExploration = 1.4
+
+// Number of random moves compared by their static evaluation in guided
+// playouts
+const guidedCandidates = 4
+
+// MCTSLimits control a Monte Carlo tree search.
+type MCTSLimits struct {
+	// Number of playouts. 0 means no limit.
+	Iterations int
+	// Time budget. 0 means no limit. Without any limit, the search runs
+	// until the context is done.
+	Time time.Duration
+	// Weight of exploring rarely visited moves against exploiting moves
+	// with a high win rate. 0 means defaultExploration.
+	Exploration float64
+	// If set, playouts prefer moves with a good static evaluation over
+	// purely random ones. Guided playouts are slower, but more realistic.
+	Guided bool
+	// Weights of the static evaluation in guided playouts. nil means
+	// DefaultEvalParams.
+	Eval *EvalParams
+	// If not empty, only these moves are considered at the root, e.g. the
+	// legal moves of a handicap game.
+	RootMoves []core.Move
+	// If not nil, the tree and the playouts only contain moves the game
+	// allows, e.g. the fixed rotations of a handicap. The board searched
+	// must be the one of the game.
+	Game *core.Game
+}
+
+// MoveVisits tells how often the search visited a move and how often
+// the player making it won the playouts below.
+type MoveVisits struct {
+	Move   core.Move
+	Visits int
+	// Sum of the playout results: 1 per win, 0.5 per draw
+	Wins float64
+}
+
+// MCTS searches with Monte Carlo tree search and UCT. It keeps its tree
+// between searches, so that the subtree of the position after the own move
+// and the opponent's reply is reused for the next search.
+type MCTS struct {
+	root *mctsNode
+	rnd  *rand.Rand
+	// Number of playouts of the last search
+	playouts int
+	// Game of the current search, see MCTSLimits.Game
+	game *core.Game
+}
+
+type mctsNode struct {
+	board  core.Board
+	move   core.Move // move leading to this node
+	parent *mctsNode
+	// Winner of the board, or 0 if the game goes on
+	winner   int
+	children []*mctsNode
+	// Moves not expanded yet. Computed on the first expansion, so that
+	// leaves do not pay for it.
+	untried  []core.Move
+	expanded bool
+	visits   int
+	// Results from the perspective of the player who made <move>
+	wins float64
+}
+
+// NewMCTS returns a searcher with an empty tree, whose random playouts
+// are determined by the seed.
+func NewMCTS(seed int64) *MCTS {
+	return &MCTS{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func newNode(b core.Board, m core.Move, parent *mctsNode) *mctsNode {
+	return &mctsNode{board: b, move: m, parent: parent, winner: b.Winner()}
+}
+
+// Search runs playouts from the board until the limits are used up and
+// returns the most visited move. If the context is done before, Search
+// stops and returns the context's error along with the best move so far.
+func (mc *MCTS) Search(ctx context.Context, b core.Board, limits MCTSLimits) (core.Move, error) {
+	mc.setRoot(b)
+	mc.game = limits.Game
+	if len(limits.RootMoves) > 0 {
+		mc.root.restrict(distinctMoves(b, limits.RootMoves))
+	}
+
+	c := limits.Exploration
+	if c == 0 {
+		c = defaultExploration
+	}
+
+	var eval *EvalParams
+	if limits.Guided {
+		eval = limits.Eval
+		if eval == nil {
+			eval = &defaultEvalParams
+		}
+	}
+
+	start := time.Now()
+	mc.playouts = 0
+	for i := 0; limits.Iterations == 0 || i < limits.Iterations; i++ {
+		if i&63 == 0 {
+			if ctx.Err() != nil {
+				break
+			}
+			if limits.Time > 0 && time.Since(start) > limits.Time {
+				break
+			}
+		}
+		mc.iterate(c, eval)
+		mc.playouts++
+	}
+
+	var best core.Move
+	if visits := mc.Visits(); len(visits) > 0 {
+		best = visits[0].Move
+	}
+	return best, ctx.Err()
+}
+
+// Visits returns the root moves of the last search, most visited first.
+func (mc *MCTS) Visits() []MoveVisits {
+	if mc.root == nil {
+		return nil
+	}
+	visits := make([]MoveVisits, len(mc.root.children))
+	for i, child := range mc.root.children {
+		visits[i] = MoveVisits{Move: child.move, Visits: child.visits, Wins: child.wins}
+	}
+	sort.SliceStable(visits, func(i, j int) bool {
+		return visits[i].Visits > visits[j].Visits
+	})
+	return visits
+}
+
+// principalVariation follows the most visited moves from the root.
+func (mc *MCTS) principalVariation() []core.Move {
+	var pv []core.Move
+	for n := mc.root; n != nil && len(n.children) > 0; {
+		best := n.children[0]
+		for _, child := range n.children[1:] {
+			if child.visits > best.visits {
+				best = child
+			}
+		}
+		pv = append(pv, best.move)
+		n = best
+	}
+	return pv
+}
+
+// setRoot makes the node of the board the root. It looks for the board
+// among the nodes two plies below the old root first, and takes the most
+// visited one if several move orders lead to it.
+func (mc *MCTS) setRoot(b core.Board) {
+	if mc.root != nil {
+		if mc.root.board == b {
+			return
+		}
+		var found *mctsNode
+		for _, child := range mc.root.children {
+			for _, grandchild := range child.children {
+				if grandchild.board == b && (found == nil || grandchild.visits > found.visits) {
+					found = grandchild
+				}
+			}
+		}
+		if found != nil {
+			found.parent = nil
+			mc.root = found
+			return
+		}
+	}
+	mc.root = newNode(b, core.Move{}, nil)
+}
+
+// restrict limits the children of the node to the given moves. Children
+// reached by one of the moves keep their subtrees, the other moves are left
+// to expand.
+func (n *mctsNode) restrict(moves []core.Move) {
+	children := n.children
+	n.children = nil
+	n.untried = nil
+	n.expanded = true
+
+	for _, m := range moves {
+		after := n.board.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+		found := false
+		for i, child := range children {
+			if child != nil && child.board == after {
+				child.move = m
+				n.children = append(n.children, child)
+				children[i] = nil
+				found = true
+				break
+			}
+		}
+		if !found {
+			n.untried = append(n.untried, m)
+		}
+	}
+
+	// Forget the playouts through the dropped children
+	for _, child := range children {
+		if child != nil {
+			n.visits -= child.visits
+		}
+	}
+}
+
+// iterate selects a leaf by UCT, expands it by one move, plays out the
+// game from there and propagates the result up to the root. Playouts are
+// guided by the evaluation, if given.
+func (mc *MCTS) iterate(c float64, eval *EvalParams) {
+	n := mc.root
+	for n.winner == 0 && n.expanded && len(n.untried) == 0 && len(n.children) > 0 {
+		n = n.selectChild(c)
+	}
+
+	if n.winner == 0 {
+		if !n.expanded {
+			n.untried = distinctMoves(n.board, mc.moves(n))
+			n.expanded = true
+		}
+		if len(n.untried) > 0 {
+			i := mc.rnd.Intn(len(n.untried))
+			m := n.untried[i]
+			n.untried[i] = n.untried[len(n.untried)-1]
+			n.untried = n.untried[:len(n.untried)-1]
+
+			child := newNode(n.board.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction), m, n)
+			n.children = append(n.children, child)
+			n = child
+		}
+	}
+
+	winner := n.winner
+	if winner == 0 {
+		winner = mc.playout(n.board, n.depth(), eval)
+	}
+
+	for ; n != nil; n = n.parent {
+		n.visits++
+		// The player who made the move leading to n is the one not to move
+		switch winner {
+		case -n.board.Turn:
+			n.wins++
+		case core.DRAW:
+			n.wins += 0.5
+		}
+	}
+}
+
+// moves returns the moves of the node's board the game of the search
+// allows, see MCTSLimits.Game.
+func (mc *MCTS) moves(n *mctsNode) []core.Move {
+	moves := core.NewPosition(n.board).Moves()
+	ply := n.depth()
+	if mc.game == nil || !mc.game.RotationFixed(ply) {
+		return moves
+	}
+	allowed := moves[:0]
+	for _, m := range moves {
+		if mc.game.Allows(m, ply) {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed
+}
+
+// depth returns the number of plies from the root to the node.
+func (n *mctsNode) depth() int {
+	depth := 0
+	for ; n.parent != nil; n = n.parent {
+		depth++
+	}
+	return depth
+}
+
+// selectChild returns the child with the highest upper confidence bound.
+func (n *mctsNode) selectChild(c float64) *mctsNode {
+	var best *mctsNode
+	bestValue := math.Inf(-1)
+	logVisits := math.Log(float64(n.visits))
+
+	for _, child := range n.children {
+		value := child.wins/float64(child.visits) + c*math.Sqrt(logVisits/float64(child.visits))
+		if value > bestValue {
+			best, bestValue = child, value
+		}
+	}
+	return best
+}
+
+// playout plays random moves from the board <ply> plies below the root
+// until the game is decided and returns the winner, or DRAW. With an
+// evaluation, it picks the best of a few random moves instead.
+func (mc *MCTS) playout(b core.Board, ply int, eval *EvalParams) int {
+	for ; ; ply++ {
+		if winner := b.Winner(); winner != 0 {
+			return winner
+		}
+
+		m := mc.randomMove(b, ply)
+		next := b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+
+		if eval != nil {
+			sign := colorSign(b.Turn)
+			bestValue := sign * eval.evaluate(next)
+			for i := 1; i < guidedCandidates; i++ {
+				m = mc.randomMove(b, ply)
+				candidate := b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+				if value := sign * eval.evaluate(candidate); value > bestValue {
+					next, bestValue = candidate, value
+				}
+			}
+		}
+		b = next
+	}
+}
+
+// randomMove returns a random move on an empty field, with a rotation the
+// game of the search allows <ply> plies below the root. The board must not
+// be full.
+func (mc *MCTS) randomMove(b core.Board, ply int) core.Move {
+	var empty [36]core.Cell
+	n := 0
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			if b.Fields[i][j] == 0 {
+				empty[n] = core.Cell{Row: i, Col: j}
+				n++
+			}
+		}
+	}
+
+	cell := empty[mc.rnd.Intn(n)]
+	if mc.game != nil && mc.game.RotationFixed(ply) {
+		return core.Move{Row: cell.Row, Col: cell.Col, Quadrant: mc.game.Handicap.Quadrant, Direction: mc.game.Handicap.Direction}
+	}
+	r := mc.rnd.Intn(8)
+	return core.Move{Row: cell.Row, Col: cell.Col, Quadrant: r / 2, Direction: r % 2}
+}
@@ -0,0 +1,103 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// openFourBoard returns a board on which white, to move, has four stones in
+// the middle of row 1 and wins immediately.
+func openFourBoard() core.Board {
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 1, 1, 1, 1, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, -1, 0, 0, -1, 0},
+		[6]int{0, 0, -1, 0, 0, 0},
+	}
+	b.Turn = core.WHITE
+	return b
+}
+
+func TestMCTSFindsWin(t *testing.T) {
+	b := openFourBoard()
+
+	mc := NewMCTS(1)
+	m, err := mc.Search(context.Background(), b, MCTSLimits{Iterations: 3000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after := b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction); after.Winner() != core.WHITE {
+		t.Error("White could win immediately, but moved ", m.Repr())
+	}
+
+	visits := 0
+	for _, v := range mc.Visits() {
+		visits += v.Visits
+	}
+	if visits != 3000 {
+		t.Error("Expected one visit of a root move per iteration, found ", visits)
+	}
+}
+
+func TestMCTSReusesTree(t *testing.T) {
+	b := core.NewBoard()
+	mc := NewMCTS(1)
+	m, _ := mc.Search(context.Background(), b, MCTSLimits{Iterations: 2000, Guided: true})
+
+	// Reply with the move the search expects
+	child := mc.root.children[0]
+	for _, c := range mc.root.children {
+		if c.move == m {
+			child = c
+		}
+	}
+	if len(child.children) == 0 {
+		t.Fatal("Expected the best move to be expanded")
+	}
+	reply := child.children[0]
+	b = reply.board
+
+	mc.Search(context.Background(), b, MCTSLimits{Iterations: 10})
+	if mc.root != reply || mc.root.parent != nil {
+		t.Fatal("Expected the subtree of the position to be reused")
+	}
+
+	// Without a matching node, the search starts from scratch
+	mc.Search(context.Background(), core.NewBoard().SetAt(0, 0), MCTSLimits{Iterations: 10})
+	if mc.root.visits != 10 {
+		t.Error("Expected a new tree, found visits: ", mc.root.visits)
+	}
+}
+
+func TestMCTSRootMoves(t *testing.T) {
+	rootMoves := []core.Move{
+		{Row: 2, Col: 2, Quadrant: core.UPPERLEFT, Direction: core.CLOCKWISE},
+		{Row: 3, Col: 3, Quadrant: core.UPPERRIGHT, Direction: core.COUNTERCLOCKWISE},
+	}
+	mc := NewMCTS(1)
+	mc.Search(context.Background(), core.NewBoard(), MCTSLimits{Iterations: 100, RootMoves: rootMoves})
+
+	visits := mc.Visits()
+	if len(visits) != 2 {
+		t.Fatal("Expected exactly the root moves to be searched: ", visits)
+	}
+	for _, v := range visits {
+		if v.Move != rootMoves[0] && v.Move != rootMoves[1] {
+			t.Error("Unexpected root move ", v.Move.Repr())
+		}
+	}
+}
+
+func TestMCTSCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewMCTS(1).Search(ctx, core.NewBoard(), MCTSLimits{}); err != context.Canceled {
+		t.Error("Expected search to be canceled, got ", err)
+	}
+}
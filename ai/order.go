@@ -0,0 +1,283 @@
+package ai
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// Ordering classes of moves, from best to worst. Within a class, moves are
+// ordered by their history score.
+const (
+	orderLosing = iota
+	orderDraw
+	orderQuiet
+	orderKiller
+	orderThreat
+	orderBlock
+	orderWinning
+)
+
+// History scores are halved once one of them exceeds this limit, so that
+// recent cutoffs count more than old ones.
+const maxHistory = 1 << 20
+
+// orderMoves sorts the moves for the search, most promising first: the
+// move from the transposition table, winning moves, moves reducing the
+// immediate threats of the opponent, moves creating an immediate threat,
+// the killer moves of the ply and the other moves by their history score.
+// Moves ending the game in a draw or a loss come last. An immediate threat
+// is a window holding four stones of a player and an empty field. The
+// returned moves are marked as forcing, see scoreMoves.
+//
+// In beam mode, the moves are instead sorted by their static evaluation
+// and only the best <Beam> ones are kept, unless they lead to the leaves
+// of the search anyway.
+func (s *searcher) orderMoves(moves []core.Move, ply, depth int, ttMove core.Move, hasTTMove bool) []scoredMove {
+	if hasTTMove && !containsMove(moves, ttMove) {
+		// A move dropped by distinctMoves, equivalent to one of the others
+		moves = append(moves, ttMove)
+	}
+	scored := s.scoreMoves(moves, ply)
+	beam := s.limits.Beam > 0 && depth > 1
+	if beam {
+		s.evaluateMoves(scored)
+	}
+	sort.Sort(byValue(scored))
+
+	if hasTTMove {
+		for i, sm := range scored {
+			if sm.move == ttMove {
+				copy(scored[1:i+1], scored[:i])
+				scored[0] = sm
+				break
+			}
+		}
+	}
+	if beam && len(scored) > s.limits.Beam {
+		scored = scored[:s.limits.Beam]
+	}
+	return scored
+}
+
+// scoreMoves values the moves by their ordering class and history score.
+// Moves creating an immediate threat without leaving the opponent one are
+// forcing, and so is the only move removing all immediate threats of the
+// opponent, which a draw does as well. Moves reaching the same position
+// count as one there, like the rotations of quadrants which look the same
+// when rotated.
+func (s *searcher) scoreMoves(moves []core.Move, ply int) []scoredMove {
+	b := s.pos.Board()
+	own, opposing := bitboards(&b)
+	if b.Turn == core.BLACK {
+		own, opposing = opposing, own
+	}
+	ownThreats, opposingThreats := countThreats(own, opposing), countThreats(opposing, own)
+	var rotated [8]rotatedStones
+	for r := range rotated {
+		rotated[r] = newRotatedStones(own, opposing, r)
+	}
+
+	scored := make([]scoredMove, len(moves))
+	// Stones after the defenses, to tell apart defenses reaching different
+	// positions
+	var defended [][2]uint64
+	defense := 0
+	for i, m := range moves {
+		after := rotated[2*m.Quadrant+m.Direction].place(rotatedFields[2*m.Quadrant+m.Direction][6*m.Row+m.Col])
+
+		class := orderQuiet
+		switch {
+		case after.ownFive && after.opposingFive:
+			class = orderDraw
+		case after.ownFive:
+			class = orderWinning
+		case after.opposingFive:
+			class = orderLosing
+		case after.opposingThreats < opposingThreats:
+			class = orderBlock
+		case after.ownThreats > ownThreats:
+			class = orderThreat
+		case m == s.killers[ply][0] || m == s.killers[ply][1]:
+			class = orderKiller
+		}
+
+		defends := opposingThreats > 0 && (class == orderDraw || class != orderLosing && after.opposingThreats == 0)
+		if defends {
+			stones := [2]uint64{after.own, after.opposing}
+			for _, d := range defended {
+				if d == stones {
+					defends = false
+				}
+			}
+			if defends {
+				defended = append(defended, stones)
+				defense = i
+			}
+		}
+
+		scored[i] = scoredMove{
+			move:    m,
+			value:   class*maxHistory*2 + s.history[historyIndex(m)],
+			index:   i,
+			class:   class,
+			forcing: class != orderLosing && class != orderDraw && after.ownThreats > ownThreats && after.opposingThreats == 0,
+			defends: defends,
+		}
+	}
+	if len(defended) == 1 {
+		scored[defense].forcing = true
+	}
+	return scored
+}
+
+// evaluateMoves values the moves by their static evaluation instead.
+func (s *searcher) evaluateMoves(scored []scoredMove) {
+	sign := colorSign(s.pos.Turn())
+	for i := range scored {
+		s.pos.Do(scored[i].move)
+		scored[i].value = sign * s.eval.evaluate(s.pos.Board())
+		s.pos.Undo()
+	}
+}
+
+// rotatedStones are the stones of the player to move and the opponent after
+// one of the rotations, see rotationMoves, before placing a stone.
+type rotatedStones struct {
+	own, opposing               uint64
+	ownThreats, opposingThreats int
+	ownFive, opposingFive       bool
+}
+
+func newRotatedStones(own, opposing uint64, r int) rotatedStones {
+	rs := rotatedStones{own: rotateBits(own, r), opposing: rotateBits(opposing, r)}
+	rs.ownThreats, rs.opposingThreats = countThreats(rs.own, rs.opposing), countThreats(rs.opposing, rs.own)
+	rs.ownFive, rs.opposingFive = hasFive(rs.own), hasFive(rs.opposing)
+	return rs
+}
+
+// place returns the stones after placing an own stone on the empty field.
+// Only the windows through the field need to be looked at.
+func (rs rotatedStones) place(field uint) rotatedStones {
+	after := rs
+	after.own |= 1 << field
+	for _, w := range fieldWindows[field] {
+		if rs.opposing&w == 0 {
+			switch bits.OnesCount64(rs.own & w) {
+			case 3:
+				after.ownThreats++
+			case 4:
+				after.ownThreats--
+				after.ownFive = true
+			}
+		} else if rs.own&w == 0 && bits.OnesCount64(rs.opposing&w) == 4 {
+			after.opposingThreats--
+		}
+	}
+	return after
+}
+
+// rotatedFields[r][f] is the bit of field f after rotation r, see
+// rotationMoves.
+var rotatedFields = makeRotatedFields()
+
+func makeRotatedFields() [8][36]uint {
+	var fields [8][36]uint
+	for r := range fields {
+		for f := range fields[r] {
+			fields[r][f] = uint(f)
+		}
+		for _, m := range rotationMoves[r] {
+			fields[r][m[0]] = m[1]
+		}
+	}
+	return fields
+}
+
+// fieldWindows[f] holds the masks of the windows containing field f.
+var fieldWindows = makeFieldWindows()
+
+func makeFieldWindows() [36][]uint64 {
+	var windows [36][]uint64
+	for _, w := range windowMasks {
+		for f := range windows {
+			if w>>uint(f)&1 != 0 {
+				windows[f] = append(windows[f], w)
+			}
+		}
+	}
+	return windows
+}
+
+// countThreats returns the number of windows holding four stones of <own>
+// and none of <opposing>.
+func countThreats(own, opposing uint64) int {
+	threats := 0
+	for _, w := range windowMasks {
+		if opposing&w == 0 && bits.OnesCount64(own&w) == 4 {
+			threats++
+		}
+	}
+	return threats
+}
+
+func hasFive(set uint64) bool {
+	for _, w := range windowMasks {
+		if set&w == w {
+			return true
+		}
+	}
+	return false
+}
+
+// recordCutoff remembers a move which caused a beta cutoff at the ply, as a
+// killer move and in the history.
+func (s *searcher) recordCutoff(m core.Move, ply, depth int) {
+	if s.killers[ply][0] != m {
+		s.killers[ply][1] = s.killers[ply][0]
+		s.killers[ply][0] = m
+	}
+
+	i := historyIndex(m)
+	s.history[i] += depth * depth
+	if s.history[i] > maxHistory {
+		for j := range s.history {
+			s.history[j] /= 2
+		}
+	}
+}
+
+// historyIndex returns the index of the move's field and rotation in the
+// history table.
+func historyIndex(m core.Move) int {
+	return 8*(6*m.Row+m.Col) + 2*m.Quadrant + m.Direction
+}
+
+type scoredMove struct {
+	move  core.Move
+	value int
+	// Position before sorting, which breaks ties
+	index int
+	// Ordering class, see orderMoves
+	class int
+	// Whether the move is forcing, see scoreMoves
+	forcing bool
+	// Whether the move removes all immediate threats of the opponent or
+	// ends the game in a draw. Of several such moves reaching the same
+	// position, only the first is marked.
+	defends bool
+}
+
+// byValue sorts moves by descending value. Unlike sort.SliceStable, it
+// needs no reflection, which matters at every node of the search.
+type byValue []scoredMove
+
+func (s byValue) Len() int      { return len(s) }
+func (s byValue) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byValue) Less(i, j int) bool {
+	if s[i].value != s[j].value {
+		return s[i].value > s[j].value
+	}
+	return s[i].index < s[j].index
+}
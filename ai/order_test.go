@@ -0,0 +1,73 @@
+package ai
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+func TestOrderMovesThreats(t *testing.T) {
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 1, 1, 1, 1, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, -1, 0, 0, -1, 0},
+		[6]int{0, 0, -1, -1, 0, 0},
+	}
+
+	// White wins first
+	b.Turn = core.WHITE
+	s := newSearcher(context.Background(), b, Limits{})
+	m := s.orderMoves(s.pos.Moves(), 0, 2, core.Move{}, false)[0].move
+	if after := b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction); after.Winner() != core.WHITE {
+		t.Error("Expected a winning move first, got ", m.Repr())
+	}
+
+	// Black defends first
+	b.Turn = core.BLACK
+	s = newSearcher(context.Background(), b, Limits{})
+	white, black := bitboards(&b)
+	m = s.orderMoves(s.pos.Moves(), 0, 2, core.Move{}, false)[0].move
+	after := b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+	whiteAfter, blackAfter := bitboards(&after)
+	if countThreats(whiteAfter, blackAfter) >= countThreats(white, black) {
+		t.Error("Expected a blocking move first, got ", m.Repr())
+	}
+
+	// The move from the table comes before everything else
+	ttMove := core.Move{Row: 0, Col: 0, Quadrant: core.LOWERRIGHT, Direction: core.CLOCKWISE}
+	if m := s.orderMoves(s.pos.Moves(), 0, 2, ttMove, true)[0].move; m != ttMove {
+		t.Error("Expected the table move first, got ", m.Repr())
+	}
+}
+
+func TestOrderMovesHistory(t *testing.T) {
+	b := core.NewBoard().SetAt(1, 1)
+	s := newSearcher(context.Background(), b, Limits{})
+
+	quiet := core.Move{Row: 5, Col: 5, Quadrant: core.UPPERLEFT, Direction: core.COUNTERCLOCKWISE}
+	killer := core.Move{Row: 0, Col: 5, Quadrant: core.UPPERRIGHT, Direction: core.CLOCKWISE}
+	s.recordCutoff(quiet, 0, 3)
+	s.recordCutoff(killer, 1, 1)
+
+	// Killers only count at their own ply
+	if ordered := s.orderMoves(s.pos.Moves(), 1, 2, core.Move{}, false); ordered[0].move != killer || ordered[1].move != quiet {
+		t.Error("Expected the killer and the move with history first, got ", ordered[0].move.Repr(), ordered[1].move.Repr())
+	}
+	if ordered := s.orderMoves(s.pos.Moves(), 2, 2, core.Move{}, false); ordered[0].move != quiet {
+		t.Error("Expected the move with the best history first, got ", ordered[0].move.Repr())
+	}
+}
+
+func TestSearchNodesReproducible(t *testing.T) {
+	b := randomEndgame(rand.New(rand.NewSource(3)), 24)
+	first, _ := Search(context.Background(), b, Limits{Depth: 3})
+	second, _ := Search(context.Background(), b, Limits{Depth: 3})
+	if first.Nodes != second.Nodes || first.Move != second.Move {
+		t.Errorf("Searches differ: %v nodes for %v, %v nodes for %v", first.Nodes, first.Move.Repr(), second.Nodes, second.Move.Repr())
+	}
+}
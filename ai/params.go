@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// EvalParams are the weights of the static evaluation. The values of won
+// positions are not part of them, as the search relies on their distance
+// from all other values.
+type EvalParams struct {
+	// Bonus for a stone in the center of a quadrant. Centers can't be moved
+	// by rotations, so they are part of many lines for the whole game.
+	Center int `json:"center"`
+	// Values of a window without opposing stones, by the number of own
+	// stones in it. A window with stones of both colors can't be completed
+	// any more and is worth nothing.
+	One   int `json:"one"`
+	Two   int `json:"two"`
+	Three int `json:"three"`
+	Four  int `json:"four"`
+	// Value of a window a rotation would complete, see RotationPercent
+	RotatedFive int `json:"rotatedFive"`
+	// A window which a single rotation turns into a longer line is worth
+	// the current line plus this percentage of the difference.
+	RotationPercent int `json:"rotationPercent"`
+}
+
+var defaultEvalParams = DefaultEvalParams()
+
+// DefaultEvalParams returns the parameters used unless others are given.
+func DefaultEvalParams() EvalParams {
+	return EvalParams{
+		Center:          10,
+		One:             1,
+		Two:             5,
+		Three:           25,
+		Four:            120,
+		RotatedFive:     600,
+		RotationPercent: 50,
+	}
+}
+
+// LoadEvalParams reads parameters from a JSON file. Parameters missing in
+// the file keep their default values.
+func LoadEvalParams(path string) (EvalParams, error) {
+	params := DefaultEvalParams()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return params, err
+	}
+	defer f.Close()
+
+	err = json.NewDecoder(f).Decode(&params)
+	return params, err
+}
+
+// Save writes the parameters to a JSON file.
+func (p EvalParams) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+func TestEvalParamsSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "penta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	params := DefaultEvalParams()
+	params.Center = 42
+	path := filepath.Join(dir, "params.json")
+	if err := params.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadEvalParams(path)
+	if err != nil || loaded != params {
+		t.Error("Expected the saved parameters, got ", loaded, err)
+	}
+
+	// Missing parameters keep their defaults
+	partial := filepath.Join(dir, "partial.json")
+	ioutil.WriteFile(partial, []byte(`{"four": 200}`), 0644)
+	loaded, err = LoadEvalParams(partial)
+	expected := DefaultEvalParams()
+	expected.Four = 200
+	if err != nil || loaded != expected {
+		t.Error("Expected defaults for missing parameters, got ", loaded, err)
+	}
+
+	if _, err := LoadEvalParams(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestEvalParamsInSearch(t *testing.T) {
+	b := core.NewBoard().SetAt(0, 0)
+
+	// Without any value for lines, only the centers count
+	params := EvalParams{Center: 1}
+	if v := params.evaluate(b.SetAt(1, 1)); v != -1 {
+		t.Error("Expected only the center to count, got ", v)
+	}
+
+	best := AlphaBeta(b, Limits{Depth: 1, Eval: &params})
+	if best.value != -1 {
+		t.Error("Expected black to take a center, got ", best.Move.Repr(), best.value)
+	}
+}
@@ -0,0 +1,209 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// ErrProofBudget is returned by ProveWin if the node budget is used up
+// before the question is answered.
+var ErrProofBudget = errors.New("node budget exhausted")
+
+// Proof and disproof numbers of decided nodes
+const pnInfinity = 1 << 30
+
+// ProofNode is a node of a proof tree. The children of a node where the
+// winner moves hold one winning move, the children of a node where the
+// loser moves hold all replies. Replies leading to the same board up to
+// symmetry of the whole board appear only once. Leaves are won positions.
+type ProofNode struct {
+	// Move leading to this node, the zero Move at the root
+	Move     core.Move
+	Children []*ProofNode
+}
+
+// Size returns the number of nodes of the tree.
+func (p *ProofNode) Size() int {
+	size := 1
+	for _, child := range p.Children {
+		size += child.Size()
+	}
+	return size
+}
+
+// String lists the moves of the tree, one per line, indented by ply.
+func (p *ProofNode) String() string {
+	var sb strings.Builder
+	var write func(n *ProofNode, ply int)
+	write = func(n *ProofNode, ply int) {
+		for _, child := range n.Children {
+			fmt.Fprintf(&sb, "%v%v\n", strings.Repeat("  ", ply), child.Move.Repr())
+			write(child, ply+1)
+		}
+	}
+	write(p, 0)
+	return sb.String()
+}
+
+type pnNode struct {
+	move     core.Move
+	pn, dn   int
+	children []*pnNode
+	expanded bool
+}
+
+// prover runs a proof-number search for the player to move at the root.
+type prover struct {
+	pos      *core.Position
+	attacker int
+	// Plies after which the attacker must have won
+	maxPlies int
+	nodes    int
+}
+
+// ProveWin answers whether the player to move can force a win with at
+// most <moves> own moves, by proof-number search. If so, it returns the
+// proof tree. If not, it returns nil.
+//
+// The search keeps the whole tree in memory. If it grows beyond <maxNodes>
+// nodes before the question is answered, ProveWin returns ErrProofBudget.
+// If the context is done before, it returns the context's error.
+func ProveWin(ctx context.Context, b core.Board, moves, maxNodes int) (*ProofNode, error) {
+	p := &prover{pos: core.NewPosition(b), attacker: b.Turn, maxPlies: 2*moves - 1}
+	root := &pnNode{pn: 1, dn: 1}
+	if winner := b.Winner(); winner != 0 || moves < 1 {
+		// A decided game can't be won by moving any more
+		return nil, nil
+	}
+
+	for root.pn != 0 && root.dn != 0 {
+		if p.nodes > maxNodes {
+			return nil, ErrProofBudget
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Descend to the most proving node
+		path := []*pnNode{root}
+		n := root
+		for n.expanded {
+			n = p.selectChild(n)
+			p.pos.Do(n.move)
+			path = append(path, n)
+		}
+
+		p.expand(n)
+
+		for i := len(path) - 1; i >= 0; i-- {
+			p.update(path[i])
+			if i > 0 {
+				p.pos.Undo()
+			}
+		}
+	}
+
+	if root.dn == 0 {
+		return nil, nil
+	}
+	return proofTree(root, true), nil
+}
+
+// attackerMoves returns whether the attacker is to move in the current
+// position.
+func (p *prover) attackerMoves() bool {
+	return p.pos.Turn() == p.attacker
+}
+
+// selectChild returns the child with the smallest proof number where the
+// attacker moves, and the one with the smallest disproof number otherwise.
+func (p *prover) selectChild(n *pnNode) *pnNode {
+	var best *pnNode
+	for _, child := range n.children {
+		if best == nil ||
+			(p.attackerMoves() && child.pn < best.pn) ||
+			(!p.attackerMoves() && child.dn < best.dn) {
+			best = child
+		}
+	}
+	return best
+}
+
+// expand adds the children of the current position to the node. Moves
+// leading to the same board up to symmetry are only added once.
+func (p *prover) expand(n *pnNode) {
+	n.expanded = true
+	seen := make(map[uint64]bool)
+
+	for _, m := range distinctMoves(p.pos.Board(), p.pos.Moves()) {
+		p.pos.Do(m)
+		key, _ := p.pos.CanonicalHash()
+		if !seen[key] {
+			seen[key] = true
+			child := &pnNode{move: m}
+			switch winner := p.pos.Board().Winner(); {
+			case winner == p.attacker:
+				child.pn, child.dn = 0, pnInfinity
+			case winner != 0 || p.pos.Ply() >= p.maxPlies:
+				// Lost, drawn or out of moves
+				child.pn, child.dn = pnInfinity, 0
+			default:
+				child.pn, child.dn = 1, 1
+			}
+			n.children = append(n.children, child)
+			p.nodes++
+		}
+		p.pos.Undo()
+
+		if len(n.children) > 0 && p.attackerMoves() && n.children[len(n.children)-1].pn == 0 {
+			// One winning move is enough
+			break
+		}
+	}
+}
+
+// update recomputes the numbers of an expanded node from its children.
+func (p *prover) update(n *pnNode) {
+	if !n.expanded {
+		return
+	}
+	if p.attackerMoves() {
+		n.pn, n.dn = pnInfinity, 0
+		for _, child := range n.children {
+			n.pn = minInt(n.pn, child.pn)
+			n.dn = minInt(n.dn+child.dn, pnInfinity)
+		}
+	} else {
+		n.pn, n.dn = 0, pnInfinity
+		for _, child := range n.children {
+			n.pn = minInt(n.pn+child.pn, pnInfinity)
+			n.dn = minInt(n.dn, child.dn)
+		}
+	}
+}
+
+// proofTree extracts the proof from a proven node.
+func proofTree(n *pnNode, attackerMoves bool) *ProofNode {
+	proof := &ProofNode{Move: n.move}
+	for _, child := range n.children {
+		if child.pn != 0 {
+			continue
+		}
+		proof.Children = append(proof.Children, proofTree(child, !attackerMoves))
+		if attackerMoves {
+			break
+		}
+	}
+	return proof
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// checkProof verifies that the tree proves a win for the attacker on the
+// board.
+func checkProof(t *testing.T, b core.Board, proof *ProofNode, attacker int) {
+	if len(proof.Children) == 0 {
+		if b.Winner() != attacker {
+			t.Errorf("Leaf of the proof is not won:\n%v", b.Repr())
+		}
+		return
+	}
+
+	if b.Turn == attacker && len(proof.Children) != 1 {
+		t.Error("Expected a single winning move, got ", len(proof.Children))
+	}
+	if b.Turn != attacker {
+		// Every reply up to symmetry must be refuted
+		replies := make(map[core.Board]bool)
+		for _, m := range core.NewPosition(b).Moves() {
+			replies[b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction).Canonical()] = true
+		}
+		if len(proof.Children) != len(replies) {
+			t.Errorf("Expected %v replies, got %v", len(replies), len(proof.Children))
+		}
+	}
+
+	for _, child := range proof.Children {
+		m := child.Move
+		checkProof(t, b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction), child, attacker)
+	}
+}
+
+func TestProveWin(t *testing.T) {
+	b := openThreeBoard()
+
+	// Same forced win as in TestFindMovesDepthTwo
+	proof, err := ProveWin(context.Background(), b, 2, 1000000)
+	if err != nil || proof == nil {
+		t.Fatal("Expected a proof, got ", err)
+	}
+	checkProof(t, b, proof, core.WHITE)
+
+	if proof, err := ProveWin(context.Background(), b, 1, 1000000); proof != nil || err != nil {
+		t.Error("White can't win immediately: ", proof, err)
+	}
+
+	// Black has no stones that could make a five in time
+	b.Turn = core.BLACK
+	if proof, err := ProveWin(context.Background(), b, 2, 1000000); proof != nil || err != nil {
+		t.Error("Black can't win within two moves: ", proof, err)
+	}
+
+	if _, err := ProveWin(context.Background(), core.NewBoard(), 3, 100); err != ErrProofBudget {
+		t.Error("Expected the budget to be exhausted, got ", err)
+	}
+}
@@ -0,0 +1,58 @@
+package ai
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// Score is the value of a position from WHITE's perspective: positive
+// scores favour WHITE, negative ones BLACK. Undecided positions are scored
+// in the units of the static evaluation, in which a stone in the center of
+// a quadrant is worth EvalParams.Center, 10 by default. A forced win scores
+// WinScore minus the number of plies until the game is won, a forced loss
+// the negation of that.
+type Score int
+
+// WinScore is the score of a won position.
+const WinScore = Score(winnerValue)
+
+// Win returns the winner and the number of plies until the game is won, if
+// the score is a forced win for either player.
+func (sc Score) Win() (winner, plies int, ok bool) {
+	switch {
+	case sc > WinScore-Score(maxPlies):
+		return core.WHITE, int(WinScore - sc), true
+	case sc < -WinScore+Score(maxPlies):
+		return core.BLACK, int(WinScore + sc), true
+	}
+	return 0, 0, false
+}
+
+func (sc Score) String() string {
+	if winner, plies, ok := sc.Win(); ok {
+		if winner == core.WHITE {
+			return fmt.Sprintf("white wins in %v", plies)
+		}
+		return fmt.Sprintf("black wins in %v", plies)
+	}
+	return fmt.Sprintf("%+d", int(sc))
+}
+
+// Result is the outcome of a search by an Engine. Engines fill in what
+// they know: moves from the opening book and random moves come with
+// nothing but the move, and only the alpha-beta engines set Score and
+// Depth.
+type Result struct {
+	Move core.Move
+	// Expected outcome if both players follow the principal variation
+	Score Score
+	// Principal variation, i.e. Move and the moves both players are
+	// expected to make after it
+	PV []core.Move
+	// Depth of the last completed iteration
+	Depth   int
+	Nodes   int64
+	Elapsed time.Duration
+}
@@ -0,0 +1,31 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+func TestScore(t *testing.T) {
+	for _, tc := range []struct {
+		score  Score
+		winner int
+		plies  int
+		repr   string
+	}{
+		{0, 0, 0, "+0"},
+		{35, 0, 0, "+35"},
+		{-120, 0, 0, "-120"},
+		{WinScore, core.WHITE, 0, "white wins in 0"},
+		{WinScore - 3, core.WHITE, 3, "white wins in 3"},
+		{-WinScore + 5, core.BLACK, 5, "black wins in 5"},
+	} {
+		winner, plies, ok := tc.score.Win()
+		if winner != tc.winner || plies != tc.plies || ok != (tc.winner != 0) {
+			t.Errorf("Unexpected win for %d: %v %v %v", int(tc.score), winner, plies, ok)
+		}
+		if tc.score.String() != tc.repr {
+			t.Errorf("Expected %q, got %q", tc.repr, tc.score.String())
+		}
+	}
+}
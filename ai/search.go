@@ -0,0 +1,504 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// Bound for search values, beyond any evaluation
+const infinity int = 2 * winnerValue
+
+// A game lasts 36 plies at most, so win values are within this distance of
+// winnerValue.
+const maxPlies int = 36
+
+// Size of the transposition table Search uses if none is given, in MB
+const defaultTableSize = 16
+
+// Limits control a search.
+type Limits struct {
+	// Number of plies to search. For Search, 0 means no limit.
+	Depth int
+	// Time budget of Search. 0 means no limit.
+	Time time.Duration
+	// If greater than 0, only the <Beam> moves with the best static
+	// evaluation are searched at each node. This forward pruning makes the
+	// search faster, but it may miss the only winning or defending move.
+	Beam int
+	// If not empty, only these moves are considered at the root, e.g. the
+	// legal moves of a handicap game.
+	RootMoves []core.Move
+	// If not nil, the search only considers the moves the game allows, also
+	// below the root, e.g. the fixed rotations of a handicap. The board
+	// searched must be the one of the game. Positions with such restrictions
+	// ahead bypass the transposition table, as the table holds the values
+	// of boards alone.
+	Game *core.Game
+	// If not nil, the search uses and fills this transposition table.
+	Table *TranspositionTable
+	// If not nil, Search reports its progress after each iteration.
+	Progress func(Info)
+	// Weights of the static evaluation. nil means DefaultEvalParams.
+	Eval *EvalParams
+	// If not nil, Search and the engines play a move from this opening book
+	// if it has one for the position, instead of searching.
+	Book *Book
+	// If greater than 0, Search solves boards with at most this many empty
+	// fields exactly, without regard to the time budget, see Solve.
+	Solve int
+	// Number of goroutines Search uses. With more than one, helper searches
+	// run in parallel and share their results through the transposition
+	// table (Lazy SMP). The result then depends on the timing of the
+	// helpers. 0 or 1 means a single, deterministic search. Exact solves
+	// always use a single goroutine.
+	Threads int
+}
+
+// Info describes the state of a search after an iteration.
+type Info struct {
+	Depth          int
+	Score          Score
+	Nodes          int64
+	NodesPerSecond int64
+	Elapsed        time.Duration
+	// Principal variation, i.e. the moves both players are expected to make
+	PV []core.Move
+}
+
+// AlphaBeta searches all moves to the given depth with alpha-beta negamax
+// and returns the best move. Its value is from WHITE's perspective, like
+// the one of FindBestMove. Won positions are valued winnerValue minus the
+// number of plies to get there, so the search prefers the fastest win and
+// the slowest loss.
+func AlphaBeta(b core.Board, limits Limits) EvaluatedMove {
+	res := alphaBeta(b, limits)
+	return EvaluatedMove{Move: res.Move, value: int(res.Score)}
+}
+
+// alphaBeta is AlphaBeta with the full result of the search.
+func alphaBeta(b core.Board, limits Limits) Result {
+	s := newSearcher(context.Background(), b, limits)
+	start := time.Now()
+
+	moves := limits.RootMoves
+	if len(moves) == 0 {
+		moves = s.moves()
+	}
+	depth := limits.Depth
+	if depth < 1 {
+		depth = 1
+	}
+
+	move, val := s.searchRoot(moves, depth)
+	return s.result(move, val, depth, start)
+}
+
+// Search deepens iteratively, one ply at a time, until the time budget or
+// the depth limit is used up or the result is a proven win or loss. It
+// returns the best move of the last completed iteration, with its score
+// and principal variation. The first iteration always completes, even if
+// it exceeds the time budget.
+//
+// If the context is done before that, Search stops and returns the
+// context's error, along with the best move found so far, if any.
+//
+// Boards with few empty fields are solved exactly instead, see Limits.Solve.
+//
+// Without a transposition table in the limits, Search uses a table of its
+// own, since each iteration profits from the results of the previous one.
+//
+// Moves from the opening book are returned without searching, at depth 0.
+func Search(ctx context.Context, b core.Board, limits Limits) (Result, error) {
+	if limits.Book != nil {
+		if m, ok := limits.Book.Probe(b, limits.RootMoves); ok {
+			return Result{Move: m}, nil
+		}
+	}
+	if limits.Table == nil {
+		limits.Table = NewTranspositionTable(defaultTableSize)
+	}
+	s := newSearcher(ctx, b, limits)
+	start := time.Now()
+
+	if empty := emptyFields(b); empty <= limits.Solve {
+		move, val := s.solve()
+		if s.stopped {
+			return Result{}, ctx.Err()
+		}
+		best := s.result(move, val, empty, start)
+		if limits.Progress != nil {
+			limits.Progress(s.info(empty, best.Score, best.Elapsed))
+		}
+		return best, nil
+	}
+
+	if limits.Threads > 1 {
+		helperCtx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+
+		for i := 1; i < limits.Threads; i++ {
+			h := newSearcher(helperCtx, b, limits)
+			h.counter = s.counter
+			if limits.Time > 0 {
+				h.deadline = start.Add(limits.Time)
+			}
+			wg.Add(1)
+			go func(startDepth int) {
+				defer wg.Done()
+				h.help(startDepth)
+			}(1 + i%2)
+		}
+	}
+
+	moves := limits.RootMoves
+	if len(moves) == 0 {
+		moves = s.moves()
+	}
+
+	maxDepth := s.maxDepth()
+	var best Result
+	for depth := 1; depth <= maxDepth || depth == 1; depth++ {
+		if ctx.Err() != nil {
+			break
+		}
+		if depth > 1 && limits.Time > 0 {
+			s.deadline = start.Add(limits.Time)
+			if time.Now().After(s.deadline) {
+				break
+			}
+		}
+
+		move, val := s.searchRoot(moves, depth)
+		if s.stopped {
+			break
+		}
+
+		best = s.result(move, val, depth, start)
+		if limits.Progress != nil {
+			limits.Progress(s.info(depth, best.Score, best.Elapsed))
+		}
+		if _, _, ok := best.Score.Win(); ok {
+			break
+		}
+	}
+	// Count the work on the unfinished iteration too
+	best.Nodes = s.nodeCount()
+	best.Elapsed = time.Since(start)
+	return best, ctx.Err()
+}
+
+// help searches with iterative deepening from the given depth on, filling
+// the transposition table for the main search of Search. Helpers starting
+// at different depths search different parts of the tree at a time.
+func (s *searcher) help(startDepth int) {
+	moves := s.limits.RootMoves
+	if len(moves) == 0 {
+		moves = s.moves()
+	}
+	for depth := startDepth; depth <= s.maxDepth() && !s.stopped; depth++ {
+		s.searchRoot(moves, depth)
+	}
+}
+
+// maxDepth returns the depth at which Search stops deepening, at most the
+// number of empty fields.
+func (s *searcher) maxDepth() int {
+	maxDepth := emptyFields(s.pos.Board())
+	if s.limits.Depth > 0 && s.limits.Depth < maxDepth {
+		maxDepth = s.limits.Depth
+	}
+	return maxDepth
+}
+
+type searcher struct {
+	ctx    context.Context
+	pos    *core.Position
+	limits Limits
+	eval   *EvalParams
+	nodes  int64
+	// Nodes of all searchers of a parallel search, updated every few nodes
+	counter *int64
+	// When set, the search stops at this point in time
+	deadline time.Time
+	stopped  bool
+	// pv[ply] is the principal variation found below the node at <ply>
+	pv [maxPlies + 1][]core.Move
+	// Two moves per ply which recently caused a beta cutoff
+	killers [maxPlies + 1][2]core.Move
+	// Cutoffs caused by each move, weighted by depth, see historyIndex
+	history [6 * 6 * 8]int
+}
+
+func newSearcher(ctx context.Context, b core.Board, limits Limits) *searcher {
+	s := &searcher{ctx: ctx, pos: core.NewPosition(b), limits: limits, eval: limits.Eval, counter: new(int64)}
+	if s.eval == nil {
+		s.eval = &defaultEvalParams
+	}
+	for ply := range s.pv {
+		s.pv[ply] = make([]core.Move, 0, maxPlies-ply)
+		// The zero Move is a legal one
+		s.killers[ply] = [2]core.Move{{Row: -1}, {Row: -1}}
+	}
+	return s
+}
+
+// shouldStop returns whether the search has to stop, checking the clock
+// and the context only every few nodes.
+func (s *searcher) shouldStop() bool {
+	if s.stopped || s.nodes&255 != 0 {
+		return s.stopped
+	}
+	atomic.AddInt64(s.counter, 256)
+
+	if !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopped = true
+	}
+	select {
+	case <-s.ctx.Done():
+		s.stopped = true
+	default:
+	}
+	return s.stopped
+}
+
+// updatePV makes m followed by the principal variation of the child node
+// the principal variation at the given ply.
+func (s *searcher) updatePV(ply int, m core.Move) {
+	s.pv[ply] = append(append(s.pv[ply][:0], m), s.pv[ply+1]...)
+}
+
+// nodeCount returns the number of nodes searched by all searchers so far.
+func (s *searcher) nodeCount() int64 {
+	return atomic.LoadInt64(s.counter) + s.nodes&255
+}
+
+func (s *searcher) info(depth int, score Score, elapsed time.Duration) Info {
+	nodes := s.nodeCount()
+	nps := int64(0)
+	if elapsed > 0 {
+		nps = int64(float64(nodes) / elapsed.Seconds())
+	}
+	pv := make([]core.Move, len(s.pv[0]))
+	copy(pv, s.pv[0])
+	return Info{Depth: depth, Score: score, Nodes: nodes, NodesPerSecond: nps, Elapsed: elapsed, PV: pv}
+}
+
+// result describes the move found by searchRoot at the given depth, whose
+// value is from the perspective of the player to move.
+func (s *searcher) result(move core.Move, val, depth int, start time.Time) Result {
+	info := s.info(depth, Score(colorSign(s.pos.Turn())*val), time.Since(start))
+	return Result{Move: move, Score: info.Score, PV: info.PV, Depth: depth, Nodes: info.Nodes, Elapsed: info.Elapsed}
+}
+
+// moves returns the moves of the current position the game of the limits
+// allows, see Limits.Game.
+func (s *searcher) moves() []core.Move {
+	moves := s.pos.Moves()
+	g, ply := s.limits.Game, s.pos.Ply()
+	if g == nil || !g.RotationFixed(ply) {
+		return moves
+	}
+	allowed := moves[:0]
+	for _, m := range moves {
+		if g.Allows(m, ply) {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed
+}
+
+// useTable returns whether the current position may use the transposition
+// table. Not while the game of the limits restricts the moves of either
+// player ahead, as the restrictions of the stronger player come first.
+func (s *searcher) useTable() bool {
+	g, ply := s.limits.Game, s.pos.Ply()
+	return s.limits.Table != nil && (g == nil || !g.RotationFixed(ply) && !g.RotationFixed(ply+1))
+}
+
+// searchRoot returns the best of the given moves and its value from the
+// perspective of the player to move.
+func (s *searcher) searchRoot(moves []core.Move, depth int) (core.Move, int) {
+	key, symmetry := s.pos.CanonicalHash()
+
+	var ttMove core.Move
+	hasTTMove := false
+	if s.useTable() {
+		if _, m, ok := s.limits.Table.probe(key, symmetry); ok {
+			// Only a move among the given ones may be tried first
+			ttMove, hasTTMove = m, containsMove(moves, m)
+		}
+	}
+
+	var best core.Move
+	alpha := -infinity
+	s.pv[0] = s.pv[0][:0]
+	for i, sm := range s.orderMoves(distinctMoves(s.pos.Board(), moves), 0, depth, ttMove, hasTTMove) {
+		m := sm.move
+		s.pos.Do(m)
+		val := -s.searchChild(sm, depth-1, -infinity, -alpha)
+		s.pos.Undo()
+
+		if s.stopped {
+			return best, alpha
+		}
+		if i == 0 || val > alpha {
+			alpha = val
+			best = m
+			s.updatePV(0, m)
+		}
+		if alpha >= winnerValue-1 {
+			// Nothing beats winning with this move
+			break
+		}
+	}
+
+	if s.useTable() && len(s.limits.RootMoves) == 0 {
+		s.limits.Table.store(key, symmetry, depth, valueToTable(alpha, 0), boundExact, best)
+	}
+	return best, alpha
+}
+
+// negamax returns the value of the current position from the perspective
+// of the player to move.
+func (s *searcher) negamax(depth, alpha, beta int) int {
+	s.nodes++
+	ply := s.pos.Ply()
+	s.pv[ply] = s.pv[ply][:0]
+	if s.shouldStop() {
+		return 0
+	}
+	b := s.pos.Board()
+
+	switch winner := b.Winner(); winner {
+	case core.DRAW:
+		return 0
+	case core.WHITE, core.BLACK:
+		return colorSign(b.Turn) * colorSign(winner) * (winnerValue - ply)
+	}
+
+	if depth == 0 {
+		return colorSign(b.Turn) * s.eval.evaluate(b)
+	}
+
+	var key uint64
+	var symmetry int
+	var ttMove core.Move
+	hasTTMove := false
+	alphaOrig := alpha
+	useTable := s.useTable()
+
+	if useTable {
+		key, symmetry = s.pos.CanonicalHash()
+		if e, m, ok := s.limits.Table.probe(key, symmetry); ok {
+			ttMove, hasTTMove = m, s.pos.At(m.Row, m.Col) == 0
+			if int(e.depth) >= depth {
+				val := valueFromTable(int(e.value), ply)
+				switch int(e.bound) {
+				case boundExact:
+					return val
+				case boundLower:
+					if val > alpha {
+						alpha = val
+					}
+				case boundUpper:
+					if val < beta {
+						beta = val
+					}
+				}
+				if alpha >= beta {
+					return val
+				}
+			}
+		}
+	}
+
+	best := -infinity
+	var bestMove core.Move
+	for _, sm := range s.orderMoves(distinctMoves(s.pos.Board(), s.moves()), ply, depth, ttMove, hasTTMove) {
+		m := sm.move
+		s.pos.Do(m)
+		val := -s.searchChild(sm, depth-1, -beta, -alpha)
+		s.pos.Undo()
+
+		if s.stopped {
+			return 0
+		}
+		if val > best {
+			best = val
+			bestMove = m
+		}
+		if val > alpha {
+			alpha = val
+			s.updatePV(ply, m)
+		}
+		if alpha >= beta {
+			s.recordCutoff(m, ply, depth)
+			break
+		}
+	}
+
+	if useTable {
+		bound := boundExact
+		if best <= alphaOrig {
+			bound = boundUpper
+		} else if best >= beta {
+			bound = boundLower
+		}
+		s.limits.Table.store(key, symmetry, depth, valueToTable(best, ply), bound, bestMove)
+	}
+	return best
+}
+
+// distinctMoves drops moves whose rotation has no effect on the board,
+// except for the first one per field, as they all lead to the same board.
+func distinctMoves(b core.Board, moves []core.Move) []core.Move {
+	distinct := make([]core.Move, 0, len(moves))
+	lastNoop := core.Move{Row: -1}
+
+	for _, m := range moves {
+		if isNoopRotation(b, m) {
+			if lastNoop.Row == m.Row && lastNoop.Col == m.Col {
+				continue
+			}
+			lastNoop = m
+		}
+		distinct = append(distinct, m)
+	}
+	return distinct
+}
+
+// isNoopRotation returns whether the rotation of the move leaves the board
+// unchanged, i.e. whether the outer fields of the quadrant are all equal
+// after placing the stone.
+func isNoopRotation(b core.Board, m core.Move) bool {
+	offY, offX := 3*(m.Quadrant/2), 3*(m.Quadrant%2)
+	ring := [8][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 2}, {2, 2}, {2, 1}, {2, 0}, {1, 0}}
+
+	val := func(i int) int {
+		row, col := offY+ring[i][0], offX+ring[i][1]
+		if row == m.Row && col == m.Col {
+			return b.Turn
+		}
+		return b.Fields[row][col]
+	}
+
+	first := val(0)
+	for i := 1; i < 8; i++ {
+		if val(i) != first {
+			return false
+		}
+	}
+	return true
+}
+
+// winsFor returns whether the search value (from WHITE's perspective) means
+// a forced win for the given color.
+func winsFor(value, color int) bool {
+	return colorSign(color)*value > winnerValue-maxPlies
+}
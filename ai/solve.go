@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// Solution is the outcome of a position with perfect play by both sides.
+type Solution struct {
+	// A best move, unless the game is over
+	Move core.Move
+	// WHITE, BLACK or DRAW
+	Winner int
+	// Number of plies until the game ends. The winner wins as fast as
+	// possible, the loser resists as long as possible. Drawn games are
+	// assumed to end with a full board.
+	Plies int
+}
+
+func (s Solution) String() string {
+	switch s.Winner {
+	case core.WHITE:
+		return fmt.Sprintf("white wins in %v", s.Plies)
+	case core.BLACK:
+		return fmt.Sprintf("black wins in %v", s.Plies)
+	}
+	return fmt.Sprintf("draw in %v", s.Plies)
+}
+
+// Solve searches the board to the end of the game and returns the exact
+// outcome. Only the root moves, the game, the table and the evaluation
+// parameters of the limits are used; the evaluation only orders the moves. Solve
+// ignores the time budget, so it should only be used with few empty
+// fields, see Limits.Solve. If the context is done before the board is
+// solved, Solve returns the context's error.
+func Solve(ctx context.Context, b core.Board, limits Limits) (Solution, error) {
+	if winner := b.Winner(); winner != 0 {
+		return Solution{Winner: winner}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return Solution{}, err
+	}
+	if limits.Table == nil {
+		limits.Table = NewTranspositionTable(defaultTableSize)
+	}
+	limits.Beam = 0
+
+	s := newSearcher(ctx, b, limits)
+	move, val := s.solve()
+	if s.stopped {
+		return Solution{}, ctx.Err()
+	}
+	return solution(b, move, val), nil
+}
+
+// solve searches the root moves to the end of the game and returns the
+// best one and its value from the perspective of the player to move.
+// Every ply fills a field, so the search never reaches the evaluation.
+func (s *searcher) solve() (core.Move, int) {
+	moves := s.limits.RootMoves
+	if len(moves) == 0 {
+		moves = s.moves()
+	}
+	return s.searchRoot(moves, emptyFields(s.pos.Board()))
+}
+
+// solution translates the value of an exact search into a Solution.
+func solution(b core.Board, move core.Move, val int) Solution {
+	switch {
+	case val > winnerValue-maxPlies:
+		return Solution{Move: move, Winner: b.Turn, Plies: winnerValue - val}
+	case val < -winnerValue+maxPlies:
+		return Solution{Move: move, Winner: -b.Turn, Plies: winnerValue + val}
+	}
+	return Solution{Move: move, Winner: core.DRAW, Plies: emptyFields(b)}
+}
+
+func emptyFields(b core.Board) int {
+	empty := 0
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			if b.Fields[i][j] == 0 {
+				empty++
+			}
+		}
+	}
+	return empty
+}
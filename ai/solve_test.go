@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// randomEndgame plays random moves from the empty board until only <empty>
+// fields are left and the game is still open.
+func randomEndgame(rnd *rand.Rand, empty int) core.Board {
+	for {
+		b := core.NewBoard()
+		for emptyFields(b) > empty && b.Winner() == 0 {
+			moves := core.NewPosition(b).Moves()
+			m := moves[rnd.Intn(len(moves))]
+			b = b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+		}
+		if b.Winner() == 0 {
+			return b
+		}
+	}
+}
+
+func TestSolveMatchesMinimax(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		b := randomEndgame(rnd, 3)
+
+		sol, err := Solve(context.Background(), b, Limits{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Plain minimax to the end only tells the winner
+		expected := minimax(b, 3)
+		var winner int
+		switch {
+		case expected > winnerValue/2:
+			winner = core.WHITE
+		case expected < -winnerValue/2:
+			winner = core.BLACK
+		default:
+			winner = core.DRAW
+		}
+		if sol.Winner != winner {
+			t.Errorf("Solution %v differs from minimax value %v for\n%v", sol, expected, b.Repr())
+		}
+
+		// The best move keeps the outcome
+		m := sol.Move
+		after, err := Solve(context.Background(), b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction), Limits{})
+		if err != nil || after.Winner != sol.Winner || (sol.Winner != core.DRAW && after.Plies != sol.Plies-1) {
+			t.Errorf("Solution %v not kept by %v, found %v", sol, m.Repr(), after)
+		}
+	}
+}
+
+func TestSolveDistance(t *testing.T) {
+	b := openThreeBoard()
+
+	// Solving the whole game takes too long, but Search stops at the same
+	// forced win as in TestFindMovesDepthTwo
+	best, _ := Search(context.Background(), b, Limits{Depth: 3})
+	if winner, plies, ok := best.Score.Win(); !ok || winner != core.WHITE || plies != 3 {
+		t.Error("Expected white to win in 3, got ", best.Score)
+	}
+
+	sol, err := Solve(context.Background(), b.SetAt(1, 4).SetAt(5, 5).SetAt(1, 0), Limits{})
+	if err != nil || sol.Winner != core.WHITE || sol.Plies != 0 {
+		t.Error("Expected a decided game, got ", sol, err)
+	}
+}
+
+func TestSearchSolvesEndgames(t *testing.T) {
+	b := randomEndgame(rand.New(rand.NewSource(2)), 5)
+
+	var infos []Info
+	best, err := Search(context.Background(), b, Limits{Depth: 1, Solve: 5, Progress: func(info Info) { infos = append(infos, info) }})
+	sol, _ := Solve(context.Background(), b, Limits{})
+	if err != nil || len(infos) != 1 || infos[0].Depth != 5 {
+		t.Fatal("Expected a single exact search, got ", infos, err)
+	}
+	if solution(b, best.Move, colorSign(b.Turn)*int(best.Score)) != sol {
+		t.Error("Search differs from solution ", sol)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Solve(ctx, randomEndgame(rand.New(rand.NewSource(3)), 8), Limits{}); err != context.Canceled {
+		t.Error("Expected the solver to be canceled, got ", err)
+	}
+}
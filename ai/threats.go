@@ -0,0 +1,174 @@
+package ai
+
+import (
+	"sort"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// Number of plies a forcing sequence is followed beyond the depth of the
+// search
+const maxThreatPlies = 8
+
+// searchChild returns the value of the position after the move, from the
+// perspective of the player to move there. If the move is forcing, see
+// scoreMoves, and the depth is used up, the sequence of threats and answers
+// it starts is followed with threatSearch instead of evaluating the board.
+func (s *searcher) searchChild(sm scoredMove, depth, alpha, beta int) int {
+	if depth == 0 && sm.forcing {
+		return s.threatSearch(maxThreatPlies, alpha, beta)
+	}
+	return s.negamax(depth, alpha, beta)
+}
+
+// threatSearch returns the value of the current position from the
+// perspective of the player to move, looking only at forcing moves.
+//
+// A player facing immediate threats has to remove all of them or draw,
+// assuming that a threat left standing wins on the next move. If only one
+// move does so, the search follows it and the player gets its value, if
+// several do, the static evaluation decides. A player without threats to
+// answer may either accept the static evaluation or make a threat. Either
+// way, an immediately winning move wins. After <plies> plies, the static
+// evaluation decides.
+//
+// Wins and losses are only reported if they are certain, so the assumption
+// is checked before the player is found to lose: the opponent needs a
+// winning reply to each of the other moves.
+func (s *searcher) threatSearch(plies, alpha, beta int) int {
+	s.nodes++
+	ply := s.pos.Ply()
+	s.pv[ply] = s.pv[ply][:0]
+	if s.shouldStop() {
+		return 0
+	}
+	b := s.pos.Board()
+
+	switch winner := b.Winner(); winner {
+	case core.DRAW:
+		return 0
+	case core.WHITE, core.BLACK:
+		return colorSign(b.Turn) * colorSign(winner) * (winnerValue - ply)
+	}
+
+	standPat := colorSign(b.Turn) * s.eval.evaluate(b)
+	if plies == 0 {
+		return standPat
+	}
+
+	own, opposing := bitboards(&b)
+	if b.Turn == core.BLACK {
+		own, opposing = opposing, own
+	}
+	threatened := countThreats(opposing, own) > 0
+
+	scored := s.scoreMoves(distinctMoves(b, s.moves()), ply)
+	var candidates []scoredMove
+	for _, sm := range scored {
+		if sm.class == orderWinning {
+			s.pv[ply+1] = s.pv[ply+1][:0]
+			s.updatePV(ply, sm.move)
+			return winnerValue - (ply + 1)
+		}
+		if (threatened && sm.defends) || (!threatened && sm.forcing) {
+			candidates = append(candidates, sm)
+		}
+	}
+
+	sort.Sort(byValue(candidates))
+
+	best := standPat
+	switch {
+	case threatened && len(candidates) == 0:
+		if val, lost := s.undefendedValue(scored, ply); lost {
+			return val
+		}
+		return standPat
+	case threatened && len(candidates) > 1:
+		return standPat
+	case threatened:
+		// The only defense has to be played, whatever it leads to
+		best = -infinity
+	case standPat >= beta:
+		return standPat
+	}
+	if best > alpha {
+		alpha = best
+	}
+
+	for _, sm := range candidates {
+		s.pos.Do(sm.move)
+		val := -s.threatSearch(plies-1, -beta, -alpha)
+		s.pos.Undo()
+
+		if s.stopped {
+			return 0
+		}
+		if val > best {
+			best = val
+		}
+		if val > alpha {
+			alpha = val
+			s.updatePV(ply, sm.move)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	if threatened && -best > winnerValue-maxPlies {
+		// The defense loses, but the other moves may not
+		val, lost := s.undefendedValue(scored, ply)
+		if !lost {
+			s.pv[ply] = s.pv[ply][:0]
+			return standPat
+		}
+		if val > best {
+			best = val
+		}
+	}
+	return best
+}
+
+// undefendedValue returns the value of the best of the moves leaving an
+// immediate threat of the opponent, if all of them lose, either at once or
+// to a winning reply. A threat left standing doesn't always win, e.g. if
+// completing it would complete a row of the player as well, or if the
+// opponent has to make a rotation breaking it.
+func (s *searcher) undefendedValue(scored []scoredMove, ply int) (int, bool) {
+	best := -(winnerValue - (ply + 1))
+	for _, sm := range scored {
+		if sm.class == orderLosing || sm.class == orderDraw {
+			continue
+		}
+		s.pos.Do(sm.move)
+		b := s.pos.Board()
+		own, opposing := bitboards(&b)
+		if b.Turn == core.BLACK {
+			own, opposing = opposing, own
+		}
+		// Without a threat left, the move reaches the position of a defense
+		threat := countThreats(own, opposing) > 0
+		wins := threat && s.canWin(ply+1)
+		s.pos.Undo()
+
+		switch {
+		case threat && !wins:
+			return 0, false
+		case threat:
+			best = -(winnerValue - (ply + 2))
+		}
+	}
+	return best, true
+}
+
+// canWin returns whether the player to move at the ply can win with the next
+// move.
+func (s *searcher) canWin(ply int) bool {
+	b := s.pos.Board()
+	for _, sm := range s.scoreMoves(distinctMoves(b, s.moves()), ply) {
+		if sm.class == orderWinning {
+			return true
+		}
+	}
+	return false
+}
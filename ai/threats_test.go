@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// doubleThreatBoard returns a board on which white's stone in the center of
+// the upper left quadrant makes two threats. White's stones on the left and
+// upper quadrants are in the middles of their sides, so no rotation breaks
+// the threats, and black can only block one of them.
+func doubleThreatBoard() core.Board {
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{-1, 1, -1, -1, 1, -1},
+		[6]int{1, 0, 1, 1, 0, 1},
+		[6]int{-1, 1, -1, -1, 1, -1},
+		[6]int{0, 1, 0, -1, 0, -1},
+		[6]int{1, 0, 1, 0, 0, 0},
+		[6]int{0, 1, 0, -1, 0, -1},
+	}
+	b.Turn = core.WHITE
+	return b
+}
+
+func TestThreatExtension(t *testing.T) {
+	b := doubleThreatBoard()
+
+	// The win takes three plies, but a search of one ply sees it
+	best := alphaBeta(b, Limits{Depth: 1})
+	if winner, plies, ok := best.Score.Win(); !ok || winner != core.WHITE || plies != 3 {
+		t.Fatal("Expected white to win in 3, got ", best.Score, " for ", best.Move.Repr())
+	}
+	if best.Move.Row != 1 || best.Move.Col != 1 {
+		t.Error("Expected the double threat, got ", best.Move.Repr())
+	}
+	if proof, err := ProveWin(context.Background(), b, 2, 100000); proof == nil || err != nil {
+		t.Error("Expected a proof of the win, got ", err)
+	}
+}
+
+func TestThreatSearch(t *testing.T) {
+	b := doubleThreatBoard().SetAt(1, 1).Rotate(core.LOWERRIGHT, core.CLOCKWISE)
+	s := newSearcher(context.Background(), b, Limits{})
+
+	// Black can't answer both threats
+	if val := s.threatSearch(maxThreatPlies, -infinity, infinity); val != -(winnerValue - 2) {
+		t.Error("Expected black to lose in 2, got ", val)
+	}
+
+	// Without plies left, the evaluation decides
+	if val := s.threatSearch(0, -infinity, infinity); val != -evaluate(b) {
+		t.Error("Expected the static evaluation, got ", val)
+	}
+
+	// So it does if there are several defenses, like rotations breaking an
+	// open four
+	b = core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 1, 1, 1, 1, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, -1, 0, 0, -1, 0},
+		[6]int{0, 0, -1, -1, 0, 0},
+	}
+	b.Turn = core.BLACK
+	s = newSearcher(context.Background(), b, Limits{})
+	if val := s.threatSearch(maxThreatPlies, -infinity, infinity); val != -evaluate(b) {
+		t.Error("Expected the static evaluation, got ", val)
+	}
+}
+
+func TestThreatSearchFollowsOnlyDefense(t *testing.T) {
+	// Without the lower half of the board, white's stone in the center of
+	// the upper left quadrant only threatens to complete the second row.
+	// After black blocks it, a stone in the center of the lower left
+	// quadrant threatens the second column, which white can complete by
+	// placing a stone or by rotating, so black's only defense fails again.
+	b := doubleThreatBoard()
+	for i := 3; i < 6; i++ {
+		b.Fields[i] = [6]int{}
+	}
+
+	best := alphaBeta(b, Limits{Depth: 1})
+	if winner, plies, ok := best.Score.Win(); !ok || winner != core.WHITE || plies != 5 {
+		t.Fatal("Expected white to win in 5, got ", best.Score, " for ", best.Move.Repr())
+	}
+	if best.Move.Row != 1 || best.Move.Col != 1 {
+		t.Error("Expected the threat, got ", best.Move.Repr())
+	}
+}
+
+func TestThreatSearchDraw(t *testing.T) {
+	// Black can't block white's threats, but a stone in the corner of the
+	// upper left quadrant completes the top row when the quadrant is
+	// rotated, along with white's diagonal
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 1, -1, -1, -1},
+		[6]int{-1, 1, -1, 1, 0, 1},
+		[6]int{1, 0, 1, 1, 1, -1},
+		[6]int{-1, -1, -1, 1, 1, 0},
+		[6]int{-1, 1, -1, -1, 1, 1},
+		[6]int{-1, -1, 0, 1, 1, 0},
+	}
+	b.Turn = core.BLACK
+	s := newSearcher(context.Background(), b, Limits{})
+
+	if val := s.threatSearch(maxThreatPlies, -infinity, infinity); val != 0 {
+		t.Error("Expected a draw, got ", val)
+	}
+	draw := core.Move{Row: 0, Col: 0, Quadrant: core.UPPERLEFT, Direction: core.CLOCKWISE}
+	if len(s.pv[0]) == 0 || s.pv[0][0] != draw {
+		t.Error("Expected the draw, got ", s.pv[0])
+	}
+}
+
+func TestThreatSearchHandicap(t *testing.T) {
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{-1, 0, 0, 0, 1, -1},
+		[6]int{1, 1, 1, -1, 0, 1},
+		[6]int{0, 1, -1, -1, -1, -1},
+		[6]int{1, 1, 0, -1, -1, 1},
+		[6]int{-1, 1, 1, 1, -1, -1},
+		[6]int{-1, 0, 1, 1, 1, -1},
+	}
+	b.Turn = core.BLACK
+
+	// Black can't remove all of white's threats
+	s := newSearcher(context.Background(), b, Limits{})
+	if val := s.threatSearch(maxThreatPlies, -infinity, infinity); val != -(winnerValue - 2) {
+		t.Error("Expected black to lose in 2, got ", val)
+	}
+
+	// But if white has to rotate the lower right quadrant counterclockwise,
+	// black can keep white from completing any of them
+	g := core.NewHandicapGame(core.Handicap{Weaker: core.BLACK, FixedRotations: 3, Quadrant: core.LOWERRIGHT, Direction: core.COUNTERCLOCKWISE})
+	g.Board = b
+	g.Moves = make([]core.Move, 1)
+	s = newSearcher(context.Background(), b, Limits{Game: &g})
+	if val := s.threatSearch(maxThreatPlies, -infinity, infinity); val != -evaluate(b) {
+		t.Error("Expected the static evaluation, got ", val)
+	}
+}
@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"sync/atomic"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// Kinds of values stored in the transposition table
+const (
+	boundExact = iota + 1
+	boundLower = iota + 1 // the value is at least the stored one (beta cutoff)
+	boundUpper = iota + 1 // the value is at most the stored one (no move raised alpha)
+)
+
+type ttEntry struct {
+	value int32
+	depth int8
+	bound int8
+	// Best move in the canonical orientation of the position
+	row, col, quadrant, direction int8
+}
+
+// An entry packed into 64 bits, see pack
+type ttSlot struct {
+	// key XOR data, so that torn writes by concurrent searches are detected
+	check uint64
+	data  uint64
+}
+
+// Size of a ttSlot in bytes
+const ttSlotSize = 16
+
+// TranspositionTable stores search results of positions, so that positions
+// reached by different move orders are only searched once. Positions are
+// keyed by their canonical hash, so that positions equal up to symmetry of
+// the whole board share one entry.
+//
+// The table has a fixed size. It can be reused across searches, as long as
+// the evaluation does not change in between. It is safe for concurrent use
+// by parallel searches.
+type TranspositionTable struct {
+	slots []ttSlot
+	mask  uint64
+}
+
+// NewTranspositionTable creates a table using at most the given number of
+// megabytes (at least one entry).
+func NewTranspositionTable(megabytes int) *TranspositionTable {
+	n := uint64(1)
+	maxEntries := uint64(megabytes) << 20 / ttSlotSize
+	for n*2 <= maxEntries {
+		n *= 2
+	}
+	return &TranspositionTable{slots: make([]ttSlot, n), mask: n - 1}
+}
+
+// Clear removes all entries. It must not be called during a search.
+func (tt *TranspositionTable) Clear() {
+	for i := range tt.slots {
+		tt.slots[i] = ttSlot{}
+	}
+}
+
+// probe returns the entry of the position, if present. Its move is
+// translated back from the canonical orientation given by the symmetry, see
+// core.Board.CanonicalHash.
+func (tt *TranspositionTable) probe(key uint64, symmetry int) (ttEntry, core.Move, bool) {
+	slot := &tt.slots[key&tt.mask]
+	data := atomic.LoadUint64(&slot.data)
+	check := atomic.LoadUint64(&slot.check)
+	if data == 0 || check^data != key {
+		return ttEntry{}, core.Move{}, false
+	}
+
+	e := unpack(data)
+	m := core.Move{Row: int(e.row), Col: int(e.col), Quadrant: int(e.quadrant), Direction: int(e.direction)}
+	return e, m.Transformed(core.InverseSymmetry(symmetry)), true
+}
+
+// store saves a result, unless the slot holds a deeper search of the same
+// position.
+func (tt *TranspositionTable) store(key uint64, symmetry, depth, value, bound int, move core.Move) {
+	slot := &tt.slots[key&tt.mask]
+	old := atomic.LoadUint64(&slot.data)
+	if atomic.LoadUint64(&slot.check)^old == key && int(unpack(old).depth) > depth {
+		return
+	}
+
+	m := move.Transformed(symmetry)
+	data := pack(ttEntry{
+		value:     int32(value),
+		depth:     int8(depth),
+		bound:     int8(bound),
+		row:       int8(m.Row),
+		col:       int8(m.Col),
+		quadrant:  int8(m.Quadrant),
+		direction: int8(m.Direction),
+	})
+	atomic.StoreUint64(&slot.data, data)
+	atomic.StoreUint64(&slot.check, key^data)
+}
+
+// pack puts the value into the lower 32 bits, followed by 8 bits each for
+// depth and bound, and the move in the next 9 bits.
+func pack(e ttEntry) uint64 {
+	move := uint64(e.row) | uint64(e.col)<<3 | uint64(e.quadrant)<<6 | uint64(e.direction)<<8
+	return uint64(uint32(e.value)) | uint64(uint8(e.depth))<<32 | uint64(uint8(e.bound))<<40 | move<<48
+}
+
+func unpack(data uint64) ttEntry {
+	move := data >> 48
+	return ttEntry{
+		value:     int32(uint32(data)),
+		depth:     int8(uint8(data >> 32)),
+		bound:     int8(uint8(data >> 40)),
+		row:       int8(move & 7),
+		col:       int8(move >> 3 & 7),
+		quadrant:  int8(move >> 6 & 3),
+		direction: int8(move >> 8 & 1),
+	}
+}
+
+// Win values depend on the distance from the root. The table stores them
+// relative to the position instead, so they stay valid in other searches.
+func valueToTable(value, ply int) int {
+	if value > winnerValue-maxPlies {
+		return value + ply
+	} else if value < -winnerValue+maxPlies {
+		return value - ply
+	}
+	return value
+}
+
+func valueFromTable(value, ply int) int {
+	if value > winnerValue-maxPlies {
+		return value - ply
+	} else if value < -winnerValue+maxPlies {
+		return value + ply
+	}
+	return value
+}
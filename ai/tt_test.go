@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+func TestNewTranspositionTable(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	if len(tt.slots) != 65536 {
+		t.Errorf("Expected 65536 entries in 1 MB, found %v", len(tt.slots))
+	}
+	if len(NewTranspositionTable(0).slots) != 1 {
+		t.Error("Expected at least one entry")
+	}
+}
+
+func TestTranspositionTableStoreProbe(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	b := core.NewBoard().SetAt(0, 1).Rotate(core.LOWERLEFT, core.CLOCKWISE)
+	m := core.Move{Row: 2, Col: 5, Quadrant: core.UPPERLEFT, Direction: core.COUNTERCLOCKWISE}
+
+	key, rotDegree := b.CanonicalHash()
+	tt.store(key, rotDegree, 3, 42, boundExact, m)
+	tt.store(key, rotDegree, 2, -7, boundLower, m)
+
+	// Rotated and mirrored boards share the entry, with the move transformed
+	// accordingly
+	for symmetry := 0; symmetry < 8; symmetry++ {
+		transformed := b
+		if symmetry >= 4 {
+			transformed = mirror(b)
+		}
+		transformed = rotate(transformed, symmetry%4)
+		transformedKey, transformedSymmetry := core.NewPosition(transformed).CanonicalHash()
+		e, found, ok := tt.probe(transformedKey, transformedSymmetry)
+		if !ok || e.value != 42 || e.depth != 3 || e.bound != boundExact {
+			t.Fatal("Expected entry for symmetry ", symmetry)
+		}
+		if expected := m.Transformed(symmetry); found != expected {
+			t.Errorf("Expected move %v for symmetry %v, found %v", expected.Repr(), symmetry, found.Repr())
+		}
+	}
+
+	tt.Clear()
+	if _, _, ok := tt.probe(key, rotDegree); ok {
+		t.Error("Expected empty table after clearing")
+	}
+}
+
+func TestAlphaBetaWithTable(t *testing.T) {
+	b := core.NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 1, 0, 0, -1, 0},
+		[6]int{0, 0, 1, 0, 0, 0},
+		[6]int{0, 0, 0, -1, 0, 0},
+		[6]int{0, 1, 0, 0, -1, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+	}
+
+	tt := NewTranspositionTable(4)
+	for depth := 1; depth <= 3; depth++ {
+		expected := AlphaBeta(b, Limits{Depth: depth}).value
+		if found := AlphaBeta(b, Limits{Depth: depth, Table: tt}).value; found != expected {
+			t.Errorf("Value %v with table differs from %v without at depth %v", found, expected, depth)
+		}
+	}
+}
+
+// rotate returns the board rotated by 90*rotDegree degrees
+func rotate(b core.Board, rotDegree int) core.Board {
+	rotated := b
+	for k := 0; k < rotDegree; k++ {
+		prev := rotated
+		for i := 0; i < 6; i++ {
+			for j := 0; j < 6; j++ {
+				rotated.Fields[j][5-i] = prev.Fields[i][j]
+			}
+		}
+	}
+	return rotated
+}
+
+// mirror returns the board mirrored from left to right
+func mirror(b core.Board) core.Board {
+	mirrored := b
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			mirrored.Fields[i][5-j] = b.Fields[i][j]
+		}
+	}
+	return mirrored
+}
@@ -0,0 +1,290 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"os"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+// TuneOptions control a tuning run of TuneTexel or TuneSPSA.
+type TuneOptions struct {
+	// Number of iterations, including those of a resumed run
+	Iterations int
+	// If not empty, the parameters are written to this file after every
+	// iteration, along with the number of iterations done. The file can be
+	// loaded with LoadEvalParams. If it exists when tuning starts, the run
+	// resumes from there instead of the given parameters.
+	Checkpoint string
+	// If not nil, called after every iteration with the number of
+	// iterations done and the new parameters. <measure> is the error of
+	// TuneTexel, or the match score of TuneSPSA.
+	Progress func(iteration int, params EvalParams, measure float64)
+}
+
+// MatchOptions control the games TuneSPSA plays in each iteration.
+type MatchOptions struct {
+	// Number of openings per iteration. Each one is played twice, with
+	// either engine moving first.
+	Openings int
+	// Number of random moves starting each game, so that the games differ
+	Plies int
+	// Depth of the engines' searches
+	Depth int
+	// Seed of the random openings
+	Seed int64
+}
+
+type checkpoint struct {
+	EvalParams
+	Iteration int `json:"iteration"`
+}
+
+// resume returns the parameters and iteration of the checkpoint file, if
+// it exists, or the given parameters otherwise.
+func resume(params EvalParams, opts TuneOptions) (EvalParams, int, error) {
+	if opts.Checkpoint == "" {
+		return params, 0, nil
+	}
+	data, err := ioutil.ReadFile(opts.Checkpoint)
+	if os.IsNotExist(err) {
+		return params, 0, nil
+	} else if err != nil {
+		return params, 0, err
+	}
+
+	cp := checkpoint{EvalParams: params}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return params, 0, err
+	}
+	return cp.EvalParams, cp.Iteration, nil
+}
+
+// finishIteration saves a checkpoint and reports the progress.
+func finishIteration(params EvalParams, iteration int, measure float64, opts TuneOptions) error {
+	if opts.Checkpoint != "" {
+		data, err := json.MarshalIndent(checkpoint{EvalParams: params, Iteration: iteration}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(opts.Checkpoint, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	if opts.Progress != nil {
+		opts.Progress(iteration, params, measure)
+	}
+	return nil
+}
+
+// weights returns pointers to all tunable parameters.
+func (p *EvalParams) weights() []*int {
+	return []*int{&p.Center, &p.One, &p.Two, &p.Three, &p.Four, &p.RotatedFive, &p.RotationPercent}
+}
+
+// clamp keeps the parameters within their meaningful ranges.
+func (p *EvalParams) clamp() {
+	for _, w := range p.weights() {
+		if *w < 0 {
+			*w = 0
+		}
+	}
+	if p.RotationPercent > 100 {
+		p.RotationPercent = 100
+	}
+}
+
+type texelPosition struct {
+	board core.Board
+	// Outcome of the game from WHITE's perspective: 1, 0.5 or 0
+	result float64
+}
+
+// texelPositions returns the undecided positions of all games with a
+// result.
+func texelPositions(games []core.Game) []texelPosition {
+	var positions []texelPosition
+	for _, g := range games {
+		var result float64
+		switch g.Result() {
+		case core.WHITE:
+			result = 1
+		case core.BLACK:
+			result = 0
+		case core.DRAW:
+			result = 0.5
+		default:
+			continue
+		}
+
+		b := core.NewBoard()
+		if !g.Handicap.IsZero() {
+			b = g.Handicap.Board()
+		}
+		for _, m := range g.Moves {
+			b = b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+			if b.Winner() == 0 {
+				positions = append(positions, texelPosition{board: b, result: result})
+			}
+		}
+	}
+	return positions
+}
+
+// texelError returns the mean squared difference between the game results
+// and the results predicted from the evaluation of the positions.
+func texelError(positions []texelPosition, params *EvalParams, scale float64) float64 {
+	sum := 0.0
+	for _, p := range positions {
+		predicted := 1 / (1 + math.Exp(-scale*float64(params.evaluate(p.board))))
+		sum += (p.result - predicted) * (p.result - predicted)
+	}
+	return sum / float64(len(positions))
+}
+
+// fitScale returns the factor translating evaluations into winning
+// chances that best matches the game results.
+func fitScale(positions []texelPosition, params *EvalParams) float64 {
+	best, bestErr := 0.0, math.Inf(1)
+	for scale := 0.0001; scale < 1; scale *= 1.1 {
+		if e := texelError(positions, params, scale); e < bestErr {
+			best, bestErr = scale, e
+		}
+	}
+	return best
+}
+
+// TuneTexel adjusts the parameters so that the evaluation predicts the
+// results of the games as well as possible (Texel's tuning method). Each
+// iteration tries to raise and lower every parameter in turn and keeps the
+// changes reducing the error. Tuning stops early when no change helps.
+func TuneTexel(games []core.Game, params EvalParams, opts TuneOptions) (EvalParams, error) {
+	positions := texelPositions(games)
+	if len(positions) == 0 {
+		return params, errors.New("no positions of finished games to tune with")
+	}
+
+	params, iteration, err := resume(params, opts)
+	if err != nil {
+		return params, err
+	}
+
+	scale := fitScale(positions, &params)
+	bestErr := texelError(positions, &params, scale)
+
+	for ; iteration < opts.Iterations; iteration++ {
+		improved := false
+		for i := range params.weights() {
+			for _, sign := range []int{1, -1} {
+				candidate := params
+				w := candidate.weights()[i]
+				*w += sign * (1 + *w/20)
+				candidate.clamp()
+
+				if e := texelError(positions, &candidate, scale); e < bestErr {
+					params, bestErr, improved = candidate, e, true
+					break
+				}
+			}
+		}
+
+		if err := finishIteration(params, iteration+1, bestErr, opts); err != nil {
+			return params, err
+		}
+		if !improved {
+			break
+		}
+	}
+	return params, nil
+}
+
+// TuneSPSA adjusts the parameters by simultaneous perturbation stochastic
+// approximation. Each iteration changes all parameters at random in both
+// directions, lets the two resulting engines play against each other and
+// moves the parameters towards the winner.
+func TuneSPSA(params EvalParams, opts TuneOptions, match MatchOptions) (EvalParams, error) {
+	params, iteration, err := resume(params, opts)
+	if err != nil {
+		return params, err
+	}
+
+	for ; iteration < opts.Iterations; iteration++ {
+		rnd := rand.New(rand.NewSource(match.Seed + int64(iteration)))
+		k := float64(iteration + 1)
+		ck := 1 / math.Pow(k, 0.101)
+		ak := 2 / math.Pow(k+10, 0.602)
+
+		plus, minus := params, params
+		weights, plusWeights, minusWeights := params.weights(), plus.weights(), minus.weights()
+		deltas := make([]int, len(weights))
+		for i, w := range weights {
+			deltas[i] = 2*rnd.Intn(2) - 1
+			step := int(math.Max(1, math.Round(ck*perturbation(*w))))
+			*plusWeights[i] += deltas[i] * step
+			*minusWeights[i] -= deltas[i] * step
+		}
+		plus.clamp()
+		minus.clamp()
+
+		score := playMatch(&plus, &minus, match, rnd)
+		for i, w := range weights {
+			*w += int(math.Round(ak * score * float64(deltas[i]) * perturbation(*w)))
+		}
+		params.clamp()
+
+		if err := finishIteration(params, iteration+1, score, opts); err != nil {
+			return params, err
+		}
+	}
+	return params, nil
+}
+
+// perturbation returns the size of the changes SPSA tries for a parameter
+// of the given value.
+func perturbation(value int) float64 {
+	return math.Max(1, math.Abs(float64(value))/10)
+}
+
+// playMatch plays the openings with both colors and returns the score of
+// the first parameters, between -1 (all lost) and 1 (all won).
+func playMatch(first, second *EvalParams, match MatchOptions, rnd *rand.Rand) float64 {
+	score := 0
+	for i := 0; i < match.Openings; i++ {
+		opening := randomOpening(match.Plies, rnd)
+		score += colorSign(playGame(opening, first, second, match.Depth))
+		score -= colorSign(playGame(opening, second, first, match.Depth))
+	}
+	return float64(score) / float64(2*match.Openings)
+}
+
+// randomOpening returns a game after the given number of random moves,
+// which do not decide it.
+func randomOpening(plies int, rnd *rand.Rand) core.Game {
+	g := core.NewGame(false)
+	for len(g.Moves) < plies {
+		moves := g.LegalMoves()
+		m := moves[rnd.Intn(len(moves))]
+		if after := g.Board.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction); after.Winner() == 0 {
+			g.Play(m)
+		}
+	}
+	return g
+}
+
+// playGame plays the game to the end, with the engines using the given
+// parameters for white and black, and returns the result.
+func playGame(g core.Game, white, black *EvalParams, depth int) int {
+	for g.Result() == 0 {
+		params := white
+		if g.Board.Turn == core.BLACK {
+			params = black
+		}
+		best := AlphaBeta(g.Board, Limits{Depth: depth, Eval: params, RootMoves: g.LegalMoves()})
+		g.Play(best.Move)
+	}
+	return g.Result()
+}
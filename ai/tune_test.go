@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jcharra/penta-go/core"
+)
+
+func tuneDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "penta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestTuneTexel(t *testing.T) {
+	dir := tuneDir(t)
+	defer os.RemoveAll(dir)
+
+	params := DefaultEvalParams()
+	rnd := rand.New(rand.NewSource(1))
+	var games []core.Game
+	for i := 0; i < 6; i++ {
+		g := randomOpening(4, rnd)
+		for g.Result() == 0 {
+			g.Play(AlphaBeta(g.Board, Limits{Depth: 1, Eval: &params}).Move)
+		}
+		games = append(games, g)
+	}
+
+	// Start far from the parameters that played the games
+	start := EvalParams{Center: 1, One: 1, Two: 1, Three: 1, Four: 1, RotatedFive: 1, RotationPercent: 1}
+	var errors []float64
+	opts := TuneOptions{
+		Iterations: 3,
+		Checkpoint: filepath.Join(dir, "texel.json"),
+		Progress: func(iteration int, params EvalParams, measure float64) {
+			errors = append(errors, measure)
+		},
+	}
+
+	tuned, err := TuneTexel(games, start, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tuned == start || len(errors) == 0 {
+		t.Fatal("Expected the parameters to change")
+	}
+	for i := 1; i < len(errors); i++ {
+		if errors[i] > errors[i-1] {
+			t.Error("Error increased: ", errors)
+		}
+	}
+
+	loaded, err := LoadEvalParams(opts.Checkpoint)
+	if err != nil || loaded != tuned {
+		t.Error("Expected the tuned parameters in the checkpoint, got ", loaded, err)
+	}
+
+	if _, err := TuneTexel(nil, start, TuneOptions{Iterations: 1}); err == nil {
+		t.Error("Expected an error without games")
+	}
+}
+
+func TestTuneSPSAResume(t *testing.T) {
+	dir := tuneDir(t)
+	defer os.RemoveAll(dir)
+
+	var iterations []int
+	opts := TuneOptions{
+		Iterations: 2,
+		Checkpoint: filepath.Join(dir, "spsa.json"),
+		Progress: func(iteration int, params EvalParams, measure float64) {
+			if measure < -1 || measure > 1 {
+				t.Error("Unexpected match score ", measure)
+			}
+			iterations = append(iterations, iteration)
+		},
+	}
+	match := MatchOptions{Openings: 1, Plies: 6, Depth: 1, Seed: 1}
+
+	if _, err := TuneSPSA(DefaultEvalParams(), opts, match); err != nil {
+		t.Fatal(err)
+	}
+
+	// Continue the run for one more iteration
+	opts.Iterations = 3
+	resumed, err := TuneSPSA(DefaultEvalParams(), opts, match)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(iterations) != 3 || iterations[2] != 3 {
+		t.Error("Expected the run to resume at the third iteration: ", iterations)
+	}
+
+	// A run without interruption ends with the same parameters
+	opts.Checkpoint = ""
+	if uninterrupted, _ := TuneSPSA(DefaultEvalParams(), opts, match); uninterrupted != resumed {
+		t.Error("Expected the resumed run to match an uninterrupted one")
+	}
+}
@@ -43,7 +43,12 @@ func (b Board) Repr() string {
 
 func (b Board) Rotate(quadrant, direction int) Board {
 	b2 := b.Copy()
+	rotateFields(&b2.Fields, quadrant, direction)
+	return b2
+}
 
+// rotateFields rotates a quadrant of the given fields in place
+func rotateFields(fields *[6][6]int, quadrant, direction int) {
 	var offX, offY int
 	switch quadrant {
 	case UPPERLEFT:
@@ -56,26 +61,38 @@ func (b Board) Rotate(quadrant, direction int) Board {
 		offX, offY = 3, 3
 	}
 
+	var quad [3][3]int
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			quad[i][j] = fields[offY+i][offX+j]
+		}
+	}
+
 	if direction == CLOCKWISE {
 		for i := 0; i < 3; i++ {
 			for j := 0; j < 3; j++ {
-				b2.Fields[offY+i][offX+j] = b.Fields[offY+2-j][offX+i]
+				fields[offY+i][offX+j] = quad[2-j][i]
 			}
 		}
 	} else if direction == COUNTERCLOCKWISE {
 		for i := 0; i < 3; i++ {
 			for j := 0; j < 3; j++ {
-				b2.Fields[offY+2-j][offX+i] = b.Fields[offY+i][offX+j]
+				fields[offY+2-j][offX+i] = quad[i][j]
 			}
 		}
 	}
-	return b2
 }
 
 func (b Board) Equals(b2 Board) bool {
 	return b.Fields == b2.Fields
 }
 
+// EqualsIgnoreSymmetry returns whether b2 equals b after one of the
+// symmetries of the whole board, see Canonical.
+func (b Board) EqualsIgnoreSymmetry(b2 Board) bool {
+	return b.Canonical().Fields == b2.Canonical().Fields
+}
+
 func (b Board) EqualsIgnoreRotation(b2 Board) bool {
 	return b.equalsRot(b2, 0) || b.equalsRot(b2, 1) || b.equalsRot(b2, 2) || b.equalsRot(b2, 3)
 }
@@ -240,3 +257,53 @@ func allEqual(val int, arr []int) bool {
 	}
 	return true
 }
+
+// Number of symmetries of the whole board: the four rotations, each with
+// and without mirroring. Mirroring turns clockwise rotations of quadrants
+// into counterclockwise ones, so it maps games to games just like the
+// rotations do.
+const symmetries = 8
+
+// Canonical returns the representative of all boards equal to b up to
+// symmetry of the whole board, i.e. rotation and mirroring (see
+// EqualsIgnoreSymmetry). Two boards are equal up to symmetry iff their
+// canonical boards are equal.
+func (b Board) Canonical() Board {
+	canonical := b
+	for symmetry := 1; symmetry < symmetries; symmetry++ {
+		transformed := b.transform(symmetry)
+		if lessFields(&transformed.Fields, &canonical.Fields) {
+			canonical = transformed
+		}
+	}
+	return canonical
+}
+
+/*
+Returns the board after the given symmetry of the whole board. Symmetries
+0 to 3 rotate the board by 90*symmetry degrees, so that
+b.equalsRot(b.transform(rotDegree), rotDegree) holds. Symmetries 4 to 7
+mirror the board from left to right first and then rotate it by
+90*(symmetry-4) degrees.
+*/
+func (b Board) transform(symmetry int) Board {
+	b2 := b
+	for rowIdx, row := range b.Fields {
+		for colIdx, val := range row {
+			c := symmetricCells[symmetry][rowIdx][colIdx]
+			b2.Fields[c.Row][c.Col] = val
+		}
+	}
+	return b2
+}
+
+func lessFields(f1, f2 *[6][6]int) bool {
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			if f1[i][j] != f2[i][j] {
+				return f1[i][j] < f2[i][j]
+			}
+		}
+	}
+	return false
+}
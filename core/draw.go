@@ -0,0 +1,55 @@
+package core
+
+// IsDeadDraw returns whether the game is certain to end in a draw, i.e.
+// whether it can be proven that neither player can ever complete five in
+// a row, no matter how stones are placed and quadrants are rotated.
+//
+// Stones never leave their quadrant, so every reachable board consists of
+// the current stones in one of the 4^4 quadrant orientations plus new stones.
+// A color can only win if for one of those orientations some window holds
+// no opposing stone and lacks no more stones than the color still gets to
+// place.
+func IsDeadDraw(b Board) bool {
+	switch b.Winner() {
+	case DRAW:
+		return true
+	case WHITE, BLACK:
+		return false
+	}
+
+	empty := 0
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			if b.Fields[i][j] == 0 {
+				empty++
+			}
+		}
+	}
+
+	// The player to move places the first of the remaining stones
+	remaining := map[int]int{
+		b.Turn:  (empty + 1) / 2,
+		-b.Turn: empty / 2,
+	}
+
+	for orientation := 0; orientation < 256; orientation++ {
+		rotated := b
+		for q := 0; q < 4; q++ {
+			turns := (orientation >> uint(2*q)) & 3
+			for k := 0; k < turns; k++ {
+				rotateFields(&rotated.Fields, q, CLOCKWISE)
+			}
+		}
+
+		for _, w := range Windows {
+			for _, color := range []int{WHITE, BLACK} {
+				own, open := rotated.windowCount(w, color)
+				if open && 5-own <= remaining[color] {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
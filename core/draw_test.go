@@ -0,0 +1,34 @@
+package core
+
+import "testing"
+
+func TestIsDeadDraw(t *testing.T) {
+	b := NewBoard()
+	if IsDeadDraw(b) {
+		t.Error("Empty board is not a dead draw")
+	}
+
+	b.Fields = [6][6]int{
+		[6]int{-1, -1, -1, 1, 1, 1},
+		[6]int{1, -1, -1, 0, -1, 1},
+		[6]int{-1, 1, -1, 1, -1, 1},
+		[6]int{1, 1, 0, -1, 1, -1},
+		[6]int{1, -1, 1, -1, 1, -1},
+		[6]int{1, -1, 1, -1, 1, -1},
+	}
+	b.Turn = WHITE
+
+	if !IsDeadDraw(b) {
+		t.Error("Expected a dead draw")
+	}
+
+	// With more empty fields, black can still complete the long diagonal
+	b.Fields[4][4] = 0
+	b.Fields[5][5] = 0
+	b.Fields[3][3] = 0
+	b.Turn = BLACK
+
+	if IsDeadDraw(b) {
+		t.Error("Expected black to still have chances")
+	}
+}
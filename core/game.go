@@ -0,0 +1,249 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Game keeps the record of a game and enforces the rules beyond a single
+// board, like the swap rule and handicaps.
+//
+// With the swap rule, the second player may take over the position after
+// the first move, i.e. play on with the first player's stones. The first
+// player then continues with the other color. This offsets the first
+// player's advantage.
+type Game struct {
+	Board    Board
+	Moves    []Move
+	SwapRule bool
+	Swapped  bool
+	Handicap Handicap
+}
+
+func NewGame(swapRule bool) Game {
+	return Game{Board: NewBoard(), Moves: make([]Move, 0), SwapRule: swapRule}
+}
+
+// NewHandicapGame starts a game from the setup of the given handicap.
+// The swap rule does not apply to handicap games.
+func NewHandicapGame(h Handicap) Game {
+	return Game{Board: h.Board(), Moves: make([]Move, 0), Handicap: h}
+}
+
+// Play checks and applies a move of the player to move.
+func (g *Game) Play(m Move) error {
+	if g.Board.Winner() != 0 {
+		return errors.New("game is already decided")
+	}
+	if m.Row < 0 || m.Row > 5 || m.Col < 0 || m.Col > 5 {
+		return fmt.Errorf("field %v|%v is not on the board", m.Row, m.Col)
+	}
+	if m.Quadrant < UPPERLEFT || m.Quadrant > LOWERRIGHT {
+		return fmt.Errorf("invalid quadrant %v", m.Quadrant)
+	}
+	if m.Direction != CLOCKWISE && m.Direction != COUNTERCLOCKWISE {
+		return fmt.Errorf("invalid direction %v", m.Direction)
+	}
+	if g.Board.Fields[m.Row][m.Col] != 0 {
+		return fmt.Errorf("field %v|%v is blocked", m.Row, m.Col)
+	}
+	if !g.Allows(m, 0) {
+		return fmt.Errorf("rotation must be Q%v R%v due to the handicap", g.Handicap.Quadrant, g.Handicap.Direction)
+	}
+
+	g.Board = g.Board.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+	g.Moves = append(g.Moves, m)
+	return nil
+}
+
+// RotationFixed returns whether the player to move <plies> moves from now
+// has to make the rotation given by the handicap, e.g. for a search looking
+// ahead. With 0 plies, it applies to the current move.
+func (g Game) RotationFixed(plies int) bool {
+	turn := g.Board.Turn
+	if plies%2 == 1 {
+		turn = -turn
+	}
+	if g.Handicap.FixedRotations == 0 || turn == g.Handicap.Weaker {
+		return false
+	}
+
+	// Count the moves the stronger player has made by then
+	moves := len(g.Moves) + plies
+	played := moves / 2
+	if g.Handicap.Board().Turn != turn {
+		played = (moves - 1) / 2
+	}
+	return played < g.Handicap.FixedRotations
+}
+
+// Allows returns whether the handicap allows the rotation of the move made
+// <plies> moves from now, see RotationFixed.
+func (g Game) Allows(m Move, plies int) bool {
+	return !g.RotationFixed(plies) || (m.Quadrant == g.Handicap.Quadrant && m.Direction == g.Handicap.Direction)
+}
+
+// LegalMoves returns all moves the player to move may make.
+func (g Game) LegalMoves() []Move {
+	legal := make([]Move, 0)
+	for _, m := range g.Board.findMoves() {
+		if g.Allows(m, 0) {
+			legal = append(legal, m)
+		}
+	}
+	return legal
+}
+
+// Successors is like FindSuccessors, but only considers legal moves.
+func (g Game) Successors() map[Board]Move {
+	found := make(map[Board]Move, 0)
+	for _, class := range MoveClasses(g.Board) {
+		for _, m := range class.Moves {
+			if g.Allows(m, 0) {
+				found[class.Board] = m
+				break
+			}
+		}
+	}
+	return found
+}
+
+// CanSwap returns whether the player to move may swap right now.
+func (g Game) CanSwap() bool {
+	return g.SwapRule && g.Handicap.IsZero() && !g.Swapped && len(g.Moves) == 1 && g.Board.Winner() == 0
+}
+
+// Swap lets the second player take over the position after the first move.
+func (g *Game) Swap() error {
+	if !g.CanSwap() {
+		return errors.New("swapping is not allowed")
+	}
+	g.Swapped = true
+	return nil
+}
+
+// FirstPlayerColor returns the color the player who opened the game plays
+// with, which changes after a swap.
+func (g Game) FirstPlayerColor() int {
+	first := g.Handicap.Board().Turn
+	if g.Swapped {
+		return -first
+	}
+	return first
+}
+
+// Record returns the game in a line based text format: an optional
+// "rule swap" line or "handicap" line in the notation of Handicap.Repr,
+// followed by one move per line in the notation of Move.Repr and a "swap"
+// line where the second player swapped.
+func (g Game) Record() string {
+	var lines []string
+	if g.SwapRule {
+		lines = append(lines, "rule swap")
+	}
+	if !g.Handicap.IsZero() {
+		lines = append(lines, "handicap "+g.Handicap.Repr())
+	}
+	for i, m := range g.Moves {
+		lines = append(lines, m.Repr())
+		if i == 0 && g.Swapped {
+			lines = append(lines, "swap")
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// ParseRecord replays a game from the format written by Record. Empty lines
+// and lines starting with '#' are ignored.
+func ParseRecord(record string) (Game, error) {
+	g := NewGame(false)
+
+	scanner := bufio.NewScanner(strings.NewReader(record))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		var err error
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case line == "rule swap":
+			if len(g.Moves) > 0 {
+				err = errors.New("rules must precede the moves")
+			}
+			g.SwapRule = true
+		case strings.HasPrefix(line, "handicap "):
+			var h Handicap
+			if len(g.Moves) > 0 {
+				err = errors.New("handicap must precede the moves")
+			} else if h, err = ParseHandicap(strings.TrimPrefix(line, "handicap ")); err == nil {
+				g = NewHandicapGame(h)
+			}
+		case line == "swap":
+			err = g.Swap()
+		default:
+			var m Move
+			if m, err = ParseMove(line); err == nil {
+				err = g.Play(m)
+			}
+		}
+
+		if err != nil {
+			return g, fmt.Errorf("line %v: %v", lineNo, err)
+		}
+	}
+	return g, nil
+}
+
+// ReadRecords reads several games in the format written by Record, separated
+// by empty lines. Parts consisting only of comments are skipped.
+func ReadRecords(r io.Reader) ([]Game, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var games []Game
+	var record []string
+	hasMoves := false
+	flush := func() error {
+		if hasMoves {
+			g, err := ParseRecord(strings.Join(record, "\n"))
+			if err != nil {
+				return fmt.Errorf("game %v: %v", len(games)+1, err)
+			}
+			games = append(games, g)
+		}
+		record, hasMoves = record[:0], false
+		return nil
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if err := flush(); err != nil {
+				return games, err
+			}
+			continue
+		}
+		record = append(record, line)
+		hasMoves = hasMoves || !strings.HasPrefix(line, "#")
+	}
+	return games, flush()
+}
+
+// Result returns the winner of the game, DRAW if the game is drawn or can't
+// be won any more, or 0 if it is still open.
+func (g Game) Result() int {
+	if w := g.Board.Winner(); w != 0 {
+		return w
+	}
+	if IsDeadDraw(g.Board) {
+		return DRAW
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGameSwap(t *testing.T) {
+	g := NewGame(true)
+	if g.CanSwap() {
+		t.Error("Swapping must not be possible before the first move")
+	}
+
+	if err := g.Play(Move{Row: 1, Col: 1, Quadrant: LOWERRIGHT, Direction: CLOCKWISE}); err != nil {
+		t.Fatal(err)
+	}
+	if !g.CanSwap() {
+		t.Error("Swapping should be possible after the first move")
+	}
+
+	board := g.Board
+	if err := g.Swap(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Board != board || g.FirstPlayerColor() != BLACK {
+		t.Error("Swapping should hand the position to the second player")
+	}
+	if g.Swap() == nil {
+		t.Error("Swapping twice must fail")
+	}
+
+	g = NewGame(false)
+	g.Play(Move{Row: 1, Col: 1, Quadrant: LOWERRIGHT, Direction: CLOCKWISE})
+	if g.Swap() == nil {
+		t.Error("Swapping without the swap rule must fail")
+	}
+}
+
+func TestGamePlayInvalid(t *testing.T) {
+	g := NewGame(false)
+	g.Play(Move{Row: 1, Col: 1, Quadrant: LOWERRIGHT, Direction: CLOCKWISE})
+
+	if g.Play(Move{Row: 1, Col: 1, Quadrant: LOWERRIGHT, Direction: CLOCKWISE}) == nil {
+		t.Error("Expected error for a blocked field")
+	}
+	if g.Play(Move{Row: 6, Col: 1, Quadrant: LOWERRIGHT, Direction: CLOCKWISE}) == nil {
+		t.Error("Expected error for a field off the board")
+	}
+	if len(g.Moves) != 1 {
+		t.Error("Invalid moves must not be recorded")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	g := NewGame(true)
+	g.Play(Move{Row: 1, Col: 1, Quadrant: LOWERRIGHT, Direction: CLOCKWISE})
+	g.Swap()
+	g.Play(Move{Row: 4, Col: 4, Quadrant: UPPERLEFT, Direction: COUNTERCLOCKWISE})
+
+	g2, err := ParseRecord(g.Record())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g2.Board != g.Board || !g2.SwapRule || !g2.Swapped || len(g2.Moves) != 2 {
+		t.Errorf("Record did not survive a round trip:\n%v", g.Record())
+	}
+
+	if _, err := ParseRecord("(1|1) Q3 R0\n(1|1) Q3 R0\n"); err == nil {
+		t.Error("Expected error for a record with an illegal move")
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+	records := `# first game
+rule swap
+(1|1) Q3 R0
+swap
+
+# a comment on its own
+
+(0|0) Q0 R1
+(5|5) Q3 R0
+`
+	games, err := ReadRecords(strings.NewReader(records))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 || !games[0].Swapped || len(games[1].Moves) != 2 {
+		t.Errorf("Unexpected games: %v", games)
+	}
+	if games[1].Result() != 0 {
+		t.Error("Expected an open game, got ", games[1].Result())
+	}
+
+	if _, err := ReadRecords(strings.NewReader("(1|1) Q3 R0\n\n(1|1) Q3 R0\n(1|1) Q3 R0\n")); err == nil || !strings.HasPrefix(err.Error(), "game 2") {
+		t.Error("Expected error for the second game, got ", err)
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Handicap describes a setup in favor of the weaker player. The weaker
+// player may get extra stones on the board before the game starts, and the
+// stronger player may have to give up the choice of rotation for their first
+// moves, always rotating the given quadrant in the given direction instead.
+//
+// If the weaker player gets extra stones, the stronger player moves first.
+type Handicap struct {
+	Weaker         int
+	Stones         []Cell
+	FixedRotations int
+	Quadrant       int
+	Direction      int
+}
+
+// Handicaps are the predefined handicaps, all in favor of BLACK.
+var Handicaps = map[string]Handicap{
+	"stone":      {Weaker: BLACK, Stones: []Cell{{1, 1}}},
+	"two-stones": {Weaker: BLACK, Stones: []Cell{{1, 1}, {4, 4}}},
+	"rotation":   {Weaker: BLACK, FixedRotations: 3, Quadrant: UPPERLEFT, Direction: CLOCKWISE},
+}
+
+// IsZero returns whether the handicap gives no advantage at all.
+func (h Handicap) IsZero() bool {
+	return len(h.Stones) == 0 && h.FixedRotations == 0
+}
+
+// Board returns the starting position of a game with this handicap.
+func (h Handicap) Board() Board {
+	b := NewBoard()
+	for _, c := range h.Stones {
+		b.Fields[c.Row][c.Col] = h.Weaker
+	}
+	if len(h.Stones) > 0 {
+		b.Turn = -h.Weaker
+	}
+	return b
+}
+
+// Repr returns the handicap in the form understood by ParseHandicap, e.g.
+// "X (1|1) (4|4) fixed 3 Q0 R0" for black getting two stones, while white
+// has to rotate the upper left quadrant clockwise for its first three moves.
+func (h Handicap) Repr() string {
+	ch := map[int]string{1: "O", -1: "X"}
+
+	parts := []string{ch[h.Weaker]}
+	for _, c := range h.Stones {
+		parts = append(parts, fmt.Sprintf("(%v|%v)", c.Row, c.Col))
+	}
+	if h.FixedRotations > 0 {
+		parts = append(parts, fmt.Sprintf("fixed %v Q%v R%v", h.FixedRotations, h.Quadrant, h.Direction))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseHandicap returns the predefined handicap of the given name, or
+// parses a custom handicap in the notation of Repr.
+func ParseHandicap(s string) (Handicap, error) {
+	if h, ok := Handicaps[s]; ok {
+		return h, nil
+	}
+
+	var h Handicap
+	tokens := strings.Fields(s)
+	if len(tokens) == 0 {
+		return h, fmt.Errorf("empty handicap")
+	}
+
+	switch tokens[0] {
+	case "O":
+		h.Weaker = WHITE
+	case "X":
+		h.Weaker = BLACK
+	default:
+		return h, fmt.Errorf("invalid color %q in handicap", tokens[0])
+	}
+
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i] == "fixed" {
+			if i+3 >= len(tokens) {
+				return h, fmt.Errorf("incomplete fixed rotation in handicap %q", s)
+			}
+			n, err := strconv.Atoi(tokens[i+1])
+			if err != nil || n < 0 {
+				return h, fmt.Errorf("invalid number of fixed rotations %q", tokens[i+1])
+			}
+			h.FixedRotations = n
+			if _, err := fmt.Sscanf(tokens[i+2]+" "+tokens[i+3], "Q%d R%d", &h.Quadrant, &h.Direction); err != nil {
+				return h, fmt.Errorf("invalid rotation %q", tokens[i+2]+" "+tokens[i+3])
+			}
+			i += 3
+			continue
+		}
+
+		var c Cell
+		if _, err := fmt.Sscanf(tokens[i], "(%d|%d)", &c.Row, &c.Col); err != nil {
+			return h, fmt.Errorf("invalid stone %q in handicap", tokens[i])
+		}
+		h.Stones = append(h.Stones, c)
+	}
+
+	return h, h.validate()
+}
+
+func (h Handicap) validate() error {
+	if h.Weaker != WHITE && h.Weaker != BLACK {
+		return fmt.Errorf("invalid color %v of the weaker player", h.Weaker)
+	}
+	seen := make(map[Cell]bool, 0)
+	for _, c := range h.Stones {
+		if c.Row < 0 || c.Row > 5 || c.Col < 0 || c.Col > 5 || seen[c] {
+			return fmt.Errorf("invalid handicap stone %v|%v", c.Row, c.Col)
+		}
+		seen[c] = true
+	}
+	if h.Quadrant < UPPERLEFT || h.Quadrant > LOWERRIGHT || (h.Direction != CLOCKWISE && h.Direction != COUNTERCLOCKWISE) {
+		return fmt.Errorf("invalid fixed rotation Q%v R%v", h.Quadrant, h.Direction)
+	}
+	if h.Board().Winner() != 0 {
+		return fmt.Errorf("handicap stones decide the game")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package core
+
+import "testing"
+
+func TestParseHandicap(t *testing.T) {
+	h, err := ParseHandicap("two-stones")
+	if err != nil || len(h.Stones) != 2 {
+		t.Error("Expected predefined handicap: ", h, err)
+	}
+
+	h = Handicap{Weaker: WHITE, Stones: []Cell{{1, 1}, {4, 1}}, FixedRotations: 2, Quadrant: LOWERLEFT, Direction: COUNTERCLOCKWISE}
+	h2, err := ParseHandicap(h.Repr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h2.Repr() != h.Repr() {
+		t.Errorf("Handicap did not survive a round trip: %v vs. %v", h2.Repr(), h.Repr())
+	}
+
+	for _, invalid := range []string{"", "Y", "X (1|1) (1|1)", "X (6|0)", "X fixed 2 Q5 R0", "X fixed 2"} {
+		if _, err := ParseHandicap(invalid); err == nil {
+			t.Errorf("Expected error for handicap %q", invalid)
+		}
+	}
+}
+
+func TestHandicapGame(t *testing.T) {
+	h := Handicaps["two-stones"]
+	g := NewHandicapGame(h)
+
+	if g.Board.Turn != WHITE || g.Board.Fields[1][1] != BLACK || g.Board.Fields[4][4] != BLACK {
+		t.Error("Unexpected handicap setup:\n", g.Board.Repr())
+	}
+	if err := ValidateHandicapPosition(g.Board, h); err != nil {
+		t.Error("Handicap setup should be valid: ", err)
+	}
+	if ValidatePosition(g.Board) == nil {
+		t.Error("Handicap setup is not a regular position")
+	}
+
+	g.Play(Move{Row: 0, Col: 0, Quadrant: UPPERRIGHT, Direction: CLOCKWISE})
+	if err := ValidateHandicapPosition(g.Board, h); err != nil {
+		t.Error("Position after first move should be valid: ", err)
+	}
+	if g.CanSwap() {
+		t.Error("No swapping in handicap games")
+	}
+}
+
+func TestHandicapFixedRotation(t *testing.T) {
+	h := Handicap{Weaker: WHITE, FixedRotations: 1, Quadrant: LOWERLEFT, Direction: CLOCKWISE}
+	g := NewHandicapGame(h)
+
+	// White is the weaker player and may rotate freely
+	if len(g.LegalMoves()) != 36*8 {
+		t.Error("Expected all moves to be legal for white")
+	}
+
+	// Looking ahead, only black's first move is restricted
+	for plies, fixed := range []bool{false, true, false, false} {
+		if g.RotationFixed(plies) != fixed {
+			t.Errorf("Expected a fixed rotation %v plies ahead: %v", plies, fixed)
+		}
+	}
+	g.Play(Move{Row: 0, Col: 0, Quadrant: UPPERRIGHT, Direction: CLOCKWISE})
+
+	// Black has to rotate the lower left quadrant clockwise once
+	if len(g.LegalMoves()) != 35 {
+		t.Errorf("Expected 35 legal moves for black, found %v", len(g.LegalMoves()))
+	}
+	for _, m := range g.Successors() {
+		if m.Quadrant != LOWERLEFT || m.Direction != CLOCKWISE {
+			t.Error("Successor with illegal rotation: ", m.Repr())
+		}
+	}
+	if g.Play(Move{Row: 5, Col: 5, Quadrant: UPPERRIGHT, Direction: CLOCKWISE}) == nil {
+		t.Error("Expected error for a free rotation")
+	}
+	g.Play(Move{Row: 5, Col: 5, Quadrant: LOWERLEFT, Direction: CLOCKWISE})
+	g.Play(Move{Row: 0, Col: 1, Quadrant: UPPERRIGHT, Direction: CLOCKWISE})
+
+	if len(g.LegalMoves()) != 33*8 {
+		t.Error("Expected free rotations after the fixed ones")
+	}
+}
@@ -0,0 +1,93 @@
+package core
+
+// Zobrist keys for every field and color, plus one for black to move.
+// They are generated from a fixed seed, so hashes are stable across runs.
+var zobristFields [6][6][2]uint64
+var zobristBlackToMove uint64
+
+// symmetricCells[symmetry][row][col] is where the field row|col ends up
+// under the symmetry of the whole board, see Board.transform.
+var symmetricCells [symmetries][6][6]Cell
+
+func init() {
+	seed := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		// splitmix64
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			zobristFields[i][j][0] = next()
+			zobristFields[i][j][1] = next()
+		}
+	}
+	zobristBlackToMove = next()
+
+	for symmetry := 0; symmetry < symmetries; symmetry++ {
+		for i := 0; i < 6; i++ {
+			for j := 0; j < 6; j++ {
+				r, c := i, j
+				if symmetry >= 4 {
+					c = 5 - c
+				}
+				for k := 0; k < symmetry%4; k++ {
+					r, c = c, 5-r
+				}
+				symmetricCells[symmetry][i][j] = Cell{Row: r, Col: c}
+			}
+		}
+	}
+}
+
+// zobristKey returns the key for a stone of the given color at row|col,
+// or 0 for an empty field.
+func zobristKey(row, col, color int) uint64 {
+	switch color {
+	case WHITE:
+		return zobristFields[row][col][0]
+	case BLACK:
+		return zobristFields[row][col][1]
+	}
+	return 0
+}
+
+// Hash returns a Zobrist hash of the board, including the player to move.
+func (b Board) Hash() uint64 {
+	var h uint64
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			h ^= zobristKey(i, j, b.Fields[i][j])
+		}
+	}
+	if b.Turn == BLACK {
+		h ^= zobristBlackToMove
+	}
+	return h
+}
+
+// CanonicalHash returns a hash that is the same for all boards equal up to
+// symmetry of the whole board, namely the smallest hash of the eight
+// symmetric boards. It also returns the symmetry yielding that hash, so
+// that moves can be translated with Move.Transformed.
+func (b Board) CanonicalHash() (uint64, int) {
+	var hashes [symmetries]uint64
+	for symmetry := range hashes {
+		hashes[symmetry] = b.transform(symmetry).Hash()
+	}
+	return minHash(hashes)
+}
+
+func minHash(hashes [symmetries]uint64) (uint64, int) {
+	minIdx := 0
+	for i := 1; i < symmetries; i++ {
+		if hashes[i] < hashes[minIdx] {
+			minIdx = i
+		}
+	}
+	return hashes[minIdx], minIdx
+}
@@ -0,0 +1,137 @@
+package core
+
+// Cell addresses a single field of the board.
+type Cell struct {
+	Row, Col int
+}
+
+// Window is a sequence of five cells in a row, a column or a diagonal,
+// i.e. a place where one of the players could get five in a row.
+type Window [5]Cell
+
+// Windows contains all 32 windows of the board: two per row and column,
+// two per long diagonal and one per short diagonal.
+var Windows = makeWindows()
+
+func makeWindows() []Window {
+	windows := make([]Window, 0, 32)
+
+	for i := 0; i < 6; i++ {
+		for start := 0; start < 2; start++ {
+			var row, col Window
+			for k := 0; k < 5; k++ {
+				row[k] = Cell{Row: i, Col: start + k}
+				col[k] = Cell{Row: start + k, Col: i}
+			}
+			windows = append(windows, row, col)
+		}
+	}
+
+	// Long diagonals
+	for start := 0; start < 2; start++ {
+		var d1, d2 Window
+		for k := 0; k < 5; k++ {
+			d1[k] = Cell{Row: start + k, Col: start + k}
+			d2[k] = Cell{Row: 5 - start - k, Col: start + k}
+		}
+		windows = append(windows, d1, d2)
+	}
+
+	// Short diagonals
+	var sd1, sd2, sd3, sd4 Window
+	for k := 0; k < 5; k++ {
+		sd1[k] = Cell{Row: k, Col: k + 1}
+		sd2[k] = Cell{Row: k + 1, Col: k}
+		sd3[k] = Cell{Row: 5 - k, Col: k + 1}
+		sd4[k] = Cell{Row: 4 - k, Col: k}
+	}
+	return append(windows, sd1, sd2, sd3, sd4)
+}
+
+// LineCounts holds the number of windows containing two, three or four
+// stones of one color. A window is open if it contains no opposing stone,
+// so it can still be completed, and blocked otherwise.
+type LineCounts struct {
+	OpenTwos, OpenThrees, OpenFours          int
+	BlockedTwos, BlockedThrees, BlockedFours int
+}
+
+// LineStats holds the line counts of both colors.
+type LineStats struct {
+	White, Black LineCounts
+}
+
+// ForColor returns the line counts of the given color.
+func (s LineStats) ForColor(color int) LineCounts {
+	if color == WHITE {
+		return s.White
+	}
+	return s.Black
+}
+
+// CountLines counts the twos, threes and fours of both colors in all windows.
+//
+// If withRotations is set, a window also counts as an open line of a color
+// if a single quadrant rotation would bring the stones into that window.
+// Each window is counted at most once per color, with the longest open line
+// any of those rotations achieves.
+func CountLines(b Board, withRotations bool) LineStats {
+	boards := []Board{b}
+	if withRotations {
+		for q := 0; q < 4; q++ {
+			boards = append(boards, b.Rotate(q, CLOCKWISE), b.Rotate(q, COUNTERCLOCKWISE))
+		}
+	}
+
+	var stats LineStats
+	for _, w := range Windows {
+		for _, color := range []int{WHITE, BLACK} {
+			counts := &stats.White
+			if color == BLACK {
+				counts = &stats.Black
+			}
+
+			own, open := b.windowCount(w, color)
+			for _, rotated := range boards[1:] {
+				rotOwn, rotOpen := rotated.windowCount(w, color)
+				if rotOpen && (!open || rotOwn > own) {
+					own, open = rotOwn, true
+				}
+			}
+			counts.add(own, open)
+		}
+	}
+	return stats
+}
+
+// windowCount returns the number of stones of the given color in the window,
+// and whether the window is free of opposing stones.
+func (b Board) windowCount(w Window, color int) (own int, open bool) {
+	open = true
+	for _, c := range w {
+		switch b.Fields[c.Row][c.Col] {
+		case color:
+			own++
+		case -color:
+			open = false
+		}
+	}
+	return own, open
+}
+
+func (lc *LineCounts) add(own int, open bool) {
+	switch {
+	case open && own == 2:
+		lc.OpenTwos++
+	case open && own == 3:
+		lc.OpenThrees++
+	case open && own == 4:
+		lc.OpenFours++
+	case !open && own == 2:
+		lc.BlockedTwos++
+	case !open && own == 3:
+		lc.BlockedThrees++
+	case !open && own == 4:
+		lc.BlockedFours++
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import "testing"
+
+func TestWindows(t *testing.T) {
+	if len(Windows) != 32 {
+		t.Errorf("Expected 32 windows, found %v", len(Windows))
+	}
+
+	seen := make(map[Window]bool, 0)
+	for _, w := range Windows {
+		if seen[w] {
+			t.Error("Duplicate window: ", w)
+		}
+		seen[w] = true
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	b := NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 1, 1, 1, 1, -1},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+	}
+
+	expected := LineCounts{OpenFours: 1, BlockedFours: 1}
+	if stats := CountLines(b, false); stats.White != expected || stats.Black != (LineCounts{}) {
+		t.Error("Unexpected line stats: ", stats)
+	}
+}
+
+func TestCountLinesWithRotations(t *testing.T) {
+	b := NewBoard()
+	b.Fields = [6][6]int{
+		[6]int{0, 1, 0, 0, 0, 0},
+		[6]int{0, 1, 0, 1, 1, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 0, 0, -1, 0, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+	}
+
+	if stats := CountLines(b, false).ForColor(WHITE); stats.OpenThrees != 2 || stats.OpenFours != 0 {
+		t.Error("Expected two open threes without rotations: ", stats)
+	}
+
+	// Rotating the upper left quadrant clockwise moves 0|1 to 1|2
+	if stats := CountLines(b, true).ForColor(WHITE); stats.OpenFours != 2 {
+		t.Error("Expected two open fours with rotations: ", stats)
+	}
+}
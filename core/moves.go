@@ -12,27 +12,95 @@ func (m Move) Repr() string {
 	return fmt.Sprintf("(%v|%v) Q%v R%v", m.Row, m.Col, m.Quadrant, m.Direction)
 }
 
-func FindSuccessors(b Board) map[Board]Move {
-	moves := b.findMoves()
+// Transformed returns the move on the board after the symmetry, see
+// Board.transform, i.e. the move leading to the transformed successor
+// board.
+func (m Move) Transformed(symmetry int) Move {
+	c := symmetricCells[symmetry][m.Row][m.Col]
+	quadrant, direction := m.Quadrant, m.Direction
+	if symmetry >= 4 {
+		// UPPERLEFT <-> UPPERRIGHT, LOWERLEFT <-> LOWERRIGHT, and the
+		// mirrored rotation turns the other way
+		quadrant, direction = quadrant^1, 1-direction
+	}
+	for k := 0; k < symmetry%4; k++ {
+		// UPPERLEFT -> UPPERRIGHT -> LOWERRIGHT -> LOWERLEFT -> UPPERLEFT
+		quadrant = [4]int{UPPERRIGHT, LOWERRIGHT, UPPERLEFT, LOWERLEFT}[quadrant]
+	}
+	return Move{Row: c.Row, Col: c.Col, Quadrant: quadrant, Direction: direction}
+}
 
-	found := make(map[Board]Move, 0)
-	for _, move := range moves {
-		bnew := b.SetAt(move.Row, move.Col).Rotate(move.Quadrant, move.Direction)
+// InverseSymmetry returns the symmetry undoing the given one, e.g. for
+// translating moves back with Move.Transformed.
+func InverseSymmetry(symmetry int) int {
+	if symmetry < 4 {
+		return (4 - symmetry) % 4
+	}
+	// Mirroring and rotating is a reflection, its own inverse
+	return symmetry
+}
 
-		present := false
-		for bo := range found {
-			if bo.EqualsIgnoreRotation(bnew) {
-				present = true
-				break
-			}
+// ParseMove parses a move in the notation of Repr
+func ParseMove(s string) (Move, error) {
+	var m Move
+	n, err := fmt.Sscanf(s, "(%d|%d) Q%d R%d", &m.Row, &m.Col, &m.Quadrant, &m.Direction)
+	if err != nil || n != 4 {
+		return m, fmt.Errorf("invalid move %q", s)
+	}
+	return m, nil
+}
+
+// MoveClass is a set of equivalent moves, i.e. moves leading to the same
+// board up to symmetry of the whole board, see Board.Canonical.
+type MoveClass struct {
+	Board Board // board after the first move of the class
+	Moves []Move
+}
+
+// Contains returns whether the move belongs to the class.
+func (c MoveClass) Contains(m Move) bool {
+	for _, move := range c.Moves {
+		if move == m {
+			return true
 		}
+	}
+	return false
+}
+
+// MoveClasses groups all legal moves into equivalence classes. Both the
+// classes and the moves within a class keep the order in which the moves
+// are generated, so the first move of each class can serve as its
+// representative.
+func MoveClasses(b Board) []MoveClass {
+	classes := make([]MoveClass, 0)
+	index := make(map[Board]int, 0)
+
+	for _, move := range b.findMoves() {
+		bnew := b.SetAt(move.Row, move.Col).Rotate(move.Quadrant, move.Direction)
+		key := bnew.Canonical()
 
-		if !present {
-			// we found a new successor board and store the move leading there
-			found[bnew] = move
+		if i, present := index[key]; present {
+			classes[i].Moves = append(classes[i].Moves, move)
+		} else {
+			index[key] = len(classes)
+			classes = append(classes, MoveClass{Board: bnew, Moves: []Move{move}})
 		}
 	}
 
+	return classes
+}
+
+// FindSuccessors returns one move for each distinct successor board
+// (up to symmetry), keyed by the board it leads to. Use MoveClasses
+// to get all moves leading to a board.
+func FindSuccessors(b Board) map[Board]Move {
+	classes := MoveClasses(b)
+
+	found := make(map[Board]Move, len(classes))
+	for _, class := range classes {
+		found[class.Board] = class.Moves[0]
+	}
+
 	return found
 }
 
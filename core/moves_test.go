@@ -6,7 +6,63 @@ func TestFindSuccessors(t *testing.T) {
 	b := NewBoard()
 	successors := FindSuccessors(b)
 
-	if len(successors) != 9 {
-		t.Errorf("Expected 9 successors, found %v: %v", len(successors), successors)
+	// FindSuccessors merges mirror-equivalent successors as well as rotated
+	// ones, so of the nine successors up to rotation, six remain
+	if len(successors) != 6 {
+		t.Errorf("Expected 6 successors, found %v: %v", len(successors), successors)
+	}
+}
+
+func TestMoveClasses(t *testing.T) {
+	b := NewBoard()
+	classes := MoveClasses(b)
+
+	// Up to symmetry, the first stone goes to one of six fields, e.g. those
+	// on and above the diagonal of the upper left quadrant
+	if len(classes) != 6 {
+		t.Errorf("Expected 6 classes, found %v", len(classes))
+	}
+
+	total := 0
+	for _, class := range classes {
+		total += len(class.Moves)
+		for _, m := range class.Moves {
+			bnew := b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+			if !bnew.EqualsIgnoreSymmetry(class.Board) {
+				t.Errorf("Move %v does not lead to the board of its class", m.Repr())
+			}
+		}
+	}
+
+	if total != 36*8 {
+		t.Errorf("Expected all %v moves to be classified, found %v", 36*8, total)
+	}
+
+	// Rotating an empty quadrant either way gives the same board
+	m1 := Move{Row: 0, Col: 0, Quadrant: LOWERRIGHT, Direction: CLOCKWISE}
+	m2 := Move{Row: 0, Col: 0, Quadrant: LOWERRIGHT, Direction: COUNTERCLOCKWISE}
+	for _, class := range classes {
+		if class.Contains(m1) != class.Contains(m2) {
+			t.Error("Expected equivalent moves in the same class")
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	b := NewBoard().SetAt(0, 1)
+	for symmetry := 0; symmetry < symmetries; symmetry++ {
+		transformed := b.transform(symmetry)
+		if symmetry < 4 && !b.equalsRot(transformed, symmetry) {
+			t.Error("Unexpected rotation by ", symmetry)
+		}
+		if transformed.Canonical() != b.Canonical() {
+			t.Error("Canonical board differs for symmetry ", symmetry)
+		}
+	}
+
+	// The mirrored board is no rotation of the board
+	mirrored := b.transform(4)
+	if b.EqualsIgnoreRotation(mirrored) || !b.EqualsIgnoreSymmetry(mirrored) {
+		t.Error("Expected the mirrored board to be equal up to symmetry only")
 	}
 }
@@ -0,0 +1,111 @@
+package core
+
+// Position is a mutable board for search code. Do and Undo change the
+// fields, the player to move and the hashes in place, which avoids the
+// copying done by SetAt and Rotate.
+type Position struct {
+	board Board
+	// Hashes of the board under each symmetry, see Board.transform
+	hashes  [symmetries]uint64
+	history []undoEntry
+}
+
+type undoEntry struct {
+	move   Move
+	hashes [symmetries]uint64
+}
+
+func NewPosition(b Board) *Position {
+	p := &Position{board: b, history: make([]undoEntry, 0, 36)}
+	for symmetry := range p.hashes {
+		p.hashes[symmetry] = b.transform(symmetry).Hash()
+	}
+	return p
+}
+
+// Board returns a copy of the current board.
+func (p *Position) Board() Board {
+	return p.board
+}
+
+func (p *Position) Turn() int {
+	return p.board.Turn
+}
+
+func (p *Position) At(row, col int) int {
+	return p.board.Fields[row][col]
+}
+
+// Hash returns the Zobrist hash of the current board, see Board.Hash.
+func (p *Position) Hash() uint64 {
+	return p.hashes[0]
+}
+
+// CanonicalHash returns the same as Board.CanonicalHash for the current board.
+func (p *Position) CanonicalHash() (uint64, int) {
+	return minHash(p.hashes)
+}
+
+// Ply returns the number of moves done and not yet undone.
+func (p *Position) Ply() int {
+	return len(p.history)
+}
+
+// Moves returns all legal moves in the current position.
+func (p *Position) Moves() []Move {
+	return p.board.findMoves()
+}
+
+// Do places a stone of the player to move and rotates the quadrant as
+// given by the move. The field must be empty.
+func (p *Position) Do(m Move) {
+	p.history = append(p.history, undoEntry{move: m, hashes: p.hashes})
+
+	color := p.board.Turn
+	p.board.Fields[m.Row][m.Col] = color
+	p.toggle(m.Row, m.Col, color)
+
+	p.rotate(m.Quadrant, m.Direction)
+
+	p.board.Turn = -color
+	for symmetry := range p.hashes {
+		p.hashes[symmetry] ^= zobristBlackToMove
+	}
+}
+
+// Undo takes back the last move done.
+func (p *Position) Undo() {
+	last := p.history[len(p.history)-1]
+	p.history = p.history[:len(p.history)-1]
+
+	rotateFields(&p.board.Fields, last.move.Quadrant, 1-last.move.Direction)
+	p.board.Fields[last.move.Row][last.move.Col] = 0
+	p.board.Turn = -p.board.Turn
+	p.hashes = last.hashes
+}
+
+// toggle adds or removes a stone at row|col to or from all hashes
+func (p *Position) toggle(row, col, color int) {
+	for symmetry := range p.hashes {
+		c := symmetricCells[symmetry][row][col]
+		p.hashes[symmetry] ^= zobristKey(c.Row, c.Col, color)
+	}
+}
+
+func (p *Position) rotate(quadrant, direction int) {
+	offY, offX := 3*(quadrant/2), 3*(quadrant%2)
+
+	for i := offY; i < offY+3; i++ {
+		for j := offX; j < offX+3; j++ {
+			p.toggle(i, j, p.board.Fields[i][j])
+		}
+	}
+
+	rotateFields(&p.board.Fields, quadrant, direction)
+
+	for i := offY; i < offY+3; i++ {
+		for j := offX; j < offX+3; j++ {
+			p.toggle(i, j, p.board.Fields[i][j])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import "testing"
+
+func TestPositionDoUndo(t *testing.T) {
+	start := NewBoard()
+	start.Fields[1][1] = WHITE
+	start.Fields[4][2] = BLACK
+
+	p := NewPosition(start)
+	b := start
+
+	moves := []Move{
+		{Row: 0, Col: 0, Quadrant: UPPERLEFT, Direction: CLOCKWISE},
+		{Row: 3, Col: 5, Quadrant: LOWERRIGHT, Direction: COUNTERCLOCKWISE},
+		{Row: 2, Col: 4, Quadrant: UPPERLEFT, Direction: COUNTERCLOCKWISE},
+		{Row: 5, Col: 0, Quadrant: LOWERLEFT, Direction: CLOCKWISE},
+	}
+
+	for _, m := range moves {
+		p.Do(m)
+		b = b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+
+		if p.Board() != b {
+			t.Errorf("Position differs after %v:\n%v\nexpected\n%v", m.Repr(), p.Board().Repr(), b.Repr())
+		}
+		if p.Hash() != b.Hash() {
+			t.Error("Incremental hash differs after ", m.Repr())
+		}
+	}
+
+	if p.Ply() != len(moves) {
+		t.Error("Unexpected ply: ", p.Ply())
+	}
+
+	for range moves {
+		p.Undo()
+	}
+
+	if p.Board() != start || p.Hash() != start.Hash() {
+		t.Error("Position not restored after undoing all moves:\n", p.Board().Repr())
+	}
+}
+
+func TestHash(t *testing.T) {
+	b := NewBoard()
+	if b.Hash() == b.SetAt(0, 0).Hash() {
+		t.Error("Hash should change after placing a stone")
+	}
+
+	b2 := b
+	b2.Turn = BLACK
+	if b.Hash() == b2.Hash() {
+		t.Error("Hash should depend on the player to move")
+	}
+}
+
+func TestCanonicalHash(t *testing.T) {
+	b := NewBoard().SetAt(0, 1).Rotate(LOWERLEFT, CLOCKWISE).SetAt(2, 4)
+	m := Move{Row: 5, Col: 3, Quadrant: UPPERLEFT, Direction: COUNTERCLOCKWISE}
+	after := b.SetAt(m.Row, m.Col).Rotate(m.Quadrant, m.Direction)
+
+	h, _ := b.CanonicalHash()
+	for symmetry := 0; symmetry < symmetries; symmetry++ {
+		transformed := b.transform(symmetry)
+
+		if ht, _ := transformed.CanonicalHash(); ht != h {
+			t.Error("Canonical hash differs for symmetry ", symmetry)
+		}
+
+		p := NewPosition(transformed)
+		if hp, _ := p.CanonicalHash(); hp != h {
+			t.Error("Canonical hash of position differs for symmetry ", symmetry)
+		}
+
+		// The transformed move leads to the transformed successor, and the
+		// inverse symmetry leads back
+		tm := m.Transformed(symmetry)
+		p.Do(tm)
+		if p.Board() != after.transform(symmetry) {
+			t.Error("Unexpected board after transformed move for symmetry ", symmetry)
+		}
+		if p.Hash() != p.Board().Hash() {
+			t.Error("Incremental hash differs for symmetry ", symmetry)
+		}
+		if tm.Transformed(InverseSymmetry(symmetry)) != m {
+			t.Error("Inverse symmetry does not restore the move for symmetry ", symmetry)
+		}
+	}
+
+	if h2, _ := b.SetAt(3, 3).CanonicalHash(); h2 == h {
+		t.Error("Canonical hash should differ for different boards")
+	}
+}
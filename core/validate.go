@@ -0,0 +1,112 @@
+package core
+
+import "fmt"
+
+// ValidatePosition returns an error if the board could not occur in a real game,
+// i.e. if it contains invalid field values, if the number of stones does not
+// match the player to move, or if the game must already have been decided
+// before the last move was made.
+func ValidatePosition(b Board) error {
+	return ValidateHandicapPosition(b, Handicap{})
+}
+
+// ValidateHandicapPosition is like ValidatePosition for games started with
+// the given handicap, i.e. it takes the extra stones into account.
+func ValidateHandicapPosition(b Board, h Handicap) error {
+	if b.Turn != WHITE && b.Turn != BLACK {
+		return fmt.Errorf("invalid player to move: %v", b.Turn)
+	}
+
+	count := map[int]int{WHITE: 0, BLACK: 0}
+	for rowIdx, row := range b.Fields {
+		for colIdx, val := range row {
+			switch val {
+			case WHITE, BLACK:
+				count[val]++
+			case 0:
+			default:
+				return fmt.Errorf("invalid value %v at %v|%v", val, rowIdx, colIdx)
+			}
+		}
+	}
+	if len(h.Stones) > 0 {
+		count[h.Weaker] -= len(h.Stones)
+	}
+
+	// The first player either has as many stones as the second player, or
+	// exactly one more.
+	first := h.Board().Turn
+	if b.Turn == first && count[first] != count[-first] {
+		return fmt.Errorf("%v white and %v black stones played, but %v to move", count[WHITE], count[BLACK], colorName(b.Turn))
+	}
+	if b.Turn != first && count[first] != count[-first]+1 {
+		return fmt.Errorf("%v white and %v black stones played, but %v to move", count[WHITE], count[BLACK], colorName(b.Turn))
+	}
+
+	if !hasFive(b) {
+		return nil
+	}
+
+	// Someone has five in a row. The game ends at that point, so the last
+	// move must have been the one completing the line.
+	if !hasUndecidedPredecessor(b) {
+		return fmt.Errorf("game was already decided before the last move")
+	}
+	return nil
+}
+
+// hasFive returns whether at least one player has five in a row.
+func hasFive(b Board) bool {
+	w := b.Winner()
+	if w == WHITE || w == BLACK {
+		return true
+	}
+	if w == DRAW {
+		// A draw is either a full board or a simultaneous five for both colors.
+		// Emptying any field tells those apart.
+		for i := 0; i < 6; i++ {
+			for j := 0; j < 6; j++ {
+				b2 := b.Copy()
+				b2.Fields[i][j] = 0
+				if b2.Winner() != 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hasUndecidedPredecessor returns whether there is a position without a
+// winner from which a single move leads to b.
+func hasUndecidedPredecessor(b Board) bool {
+	lastPlayer := -b.Turn
+
+	for q := 0; q < 4; q++ {
+		for _, dir := range []int{CLOCKWISE, COUNTERCLOCKWISE} {
+			// Undo the rotation by turning the quadrant the other way
+			unrotated := b.Rotate(q, 1-dir)
+			for i := 0; i < 6; i++ {
+				for j := 0; j < 6; j++ {
+					if unrotated.Fields[i][j] != lastPlayer {
+						continue
+					}
+					prev := unrotated.Copy()
+					prev.Fields[i][j] = 0
+					prev.Turn = lastPlayer
+					if prev.Winner() == 0 {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func colorName(color int) string {
+	if color == WHITE {
+		return "white"
+	}
+	return "black"
+}
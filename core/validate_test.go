@@ -0,0 +1,61 @@
+package core
+
+import "testing"
+
+func TestValidatePosition(t *testing.T) {
+	b := NewBoard()
+	if err := ValidatePosition(b); err != nil {
+		t.Error("Empty board should be valid: ", err)
+	}
+
+	b = b.SetAt(2, 2).Rotate(UPPERRIGHT, CLOCKWISE)
+	if err := ValidatePosition(b); err != nil {
+		t.Error("Board after one move should be valid: ", err)
+	}
+
+	b.Turn = WHITE
+	if ValidatePosition(b) == nil {
+		t.Error("Expected error for wrong player to move")
+	}
+
+	b = NewBoard()
+	b.Fields[3][3] = 2
+	if ValidatePosition(b) == nil {
+		t.Error("Expected error for invalid field value")
+	}
+}
+
+func TestValidateDecidedPosition(t *testing.T) {
+	b := NewBoard()
+
+	// White has just completed a row
+	b.Fields = [6][6]int{
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{0, 1, 1, 1, 1, 1},
+		[6]int{0, 0, 0, 0, 0, 0},
+		[6]int{-1, -1, 0, 0, 0, 0},
+		[6]int{0, 0, 0, -1, -1, 0},
+		[6]int{0, 0, 0, 0, 0, 0},
+	}
+	b.Turn = BLACK
+
+	if err := ValidatePosition(b); err != nil {
+		t.Error("Winning position should be valid: ", err)
+	}
+
+	// No single move can complete all of these rows at once, since
+	// each of them would stay complete after undoing any rotation.
+	b.Fields = [6][6]int{
+		[6]int{1, 1, 1, 1, 1, 1},
+		[6]int{1, 1, 1, 1, 1, 1},
+		[6]int{1, 1, 1, 1, 1, 1},
+		[6]int{-1, -1, -1, -1, -1, -1},
+		[6]int{-1, -1, -1, -1, -1, -1},
+		[6]int{-1, -1, -1, -1, -1, -1},
+	}
+	b.Turn = WHITE
+
+	if ValidatePosition(b) == nil {
+		t.Error("Expected error for a game continued after a win")
+	}
+}
@@ -2,11 +2,14 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jcharra/penta-go/ai"
 	"github.com/jcharra/penta-go/core"
@@ -17,9 +20,91 @@ func main() {
 	fmt.Println("Welcome to Pentago")
 
 	interactive := flag.Bool("i", false, "interactive")
+	swapRule := flag.Bool("swap", false, "allow the second player to swap after the first move")
+	thinkTime := flag.Duration("time", time.Second, "time the computer thinks about each move")
+	verbose := flag.Bool("v", false, "show the progress of the computer's search")
+	hashSize := flag.Int("hash", 16, "size of the computer's transposition table in MB")
+	threads := flag.Int("threads", runtime.NumCPU(), "number of CPU cores the computer uses for its search (1 for reproducible play)")
+	engineName := flag.String("engine", ai.EngineNames[0], "the computer's engine: "+strings.Join(ai.EngineNames, ", "))
+	evalPath := flag.String("eval", "", "JSON file with the weights of the computer's evaluation")
+	solveEmpty := flag.Int("solve", 8, "the computer solves the game exactly once at most this many fields are empty")
+	bookPath := flag.String("book", "", "opening book the computer plays from")
+	buildBook := flag.String("build-book", "", "build an opening book into this file instead of playing, from -records if given, otherwise by searching each position for -time")
+	bookPlies := flag.Int("book-plies", 4, "number of plies covered by a book built with -build-book")
+	bookWidth := flag.Int("book-width", 2, "maximum number of moves per position of a book built by searching")
+	proveMoves := flag.Int("prove", 0, "instead of playing, prove whether the player to move after the first game in -records can force a win within this many moves")
+	tuneMethod := flag.String("tune", "", "tune the evaluation instead of playing: texel (needs -records) or spsa")
+	recordsPath := flag.String("records", "", "file with game records, separated by empty lines, for -tune texel, -build-book and -prove")
+	tuneOut := flag.String("tune-out", "params.json", "file receiving the tuned evaluation; an existing one resumes the tuning")
+	tuneIterations := flag.Int("tune-iterations", 100, "number of tuning iterations")
+	handicapName := flag.String("handicap", "", "give yourself a handicap: stone, two-stones, rotation or a custom one like 'X (1|1) fixed 2 Q0 R1'")
 
 	flag.Parse()
 
+	if _, err := ai.NewEngine(*engineName); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var eval *ai.EvalParams
+	if *evalPath != "" {
+		params, err := ai.LoadEvalParams(*evalPath)
+		if err != nil {
+			fmt.Println("Invalid evaluation parameters:", err)
+			os.Exit(1)
+		}
+		eval = &params
+	}
+
+	if *tuneMethod != "" {
+		start := ai.DefaultEvalParams()
+		if eval != nil {
+			start = *eval
+		}
+		if err := tune(*tuneMethod, *recordsPath, start, ai.TuneOptions{Iterations: *tuneIterations, Checkpoint: *tuneOut}); err != nil {
+			fmt.Println("Tuning failed:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *proveMoves > 0 {
+		if err := prove(*recordsPath, *proveMoves); err != nil {
+			fmt.Println("Proof failed:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *buildBook != "" {
+		limits := ai.Limits{Time: *thinkTime, Threads: *threads, Eval: eval}
+		opts := ai.BookOptions{Plies: *bookPlies, Width: *bookWidth, Margin: 50, Limits: limits}
+		if err := writeBook(*buildBook, *recordsPath, opts); err != nil {
+			fmt.Println("Building the book failed:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var book *ai.Book
+	if *bookPath != "" {
+		var err error
+		if book, err = ai.LoadBook(*bookPath); err != nil {
+			fmt.Println("Invalid opening book:", err)
+			os.Exit(1)
+		}
+	}
+
+	var handicap core.Handicap
+	if *handicapName != "" {
+		var err error
+		handicap, err = core.ParseHandicap(*handicapName)
+		if err != nil {
+			fmt.Println("Invalid handicap:", err)
+			os.Exit(1)
+		}
+	}
+
 	if *interactive {
 		fmt.Println("Starting interactive play ...")
 		scanner := bufio.NewScanner(os.Stdin)
@@ -38,13 +123,46 @@ func main() {
 		}
 
 		fmt.Println("Starting game")
-		b := core.NewBoard()
-		for b.Winner() == 0 {
+		g := core.NewGame(*swapRule)
+		if !handicap.IsZero() {
+			handicap.Weaker = color
+			g = core.NewHandicapGame(handicap)
+		}
+		swapOffered := false
+		table := ai.NewTranspositionTable(*hashSize)
+		engine, _ := ai.NewEngine(*engineName)
+		for g.Board.Winner() == 0 && !core.IsDeadDraw(g.Board) {
+			b := g.Board
 			fmt.Printf("\nBoard:\n%v\n", b.Repr())
 
+			if g.CanSwap() && !swapOffered {
+				swapOffered = true
+				var swap bool
+				if b.Turn == color {
+					fmt.Println("Do you want to swap and take over white's position (y/n)?")
+					scanner.Scan()
+					swap = strings.TrimSpace(scanner.Text()) == "y"
+				} else {
+					swap = ai.ShouldSwap(b, eval)
+					if swap {
+						fmt.Println("I swap and take over white's position")
+					}
+				}
+
+				if swap {
+					g.Swap()
+					color = -color
+					continue
+				}
+			}
+
 			if b.Turn == color {
-				fmt.Println("Your move (row, col, e.g. '0 5')?")
+				fmt.Println("Your move (row, col, e.g. '0 5', or 'hint')?")
 				scanner.Scan()
+				if strings.TrimSpace(scanner.Text()) == "hint" {
+					printHints(b, ai.Limits{Time: *thinkTime, RootMoves: g.LegalMoves(), Game: &g, Table: table, Eval: eval})
+					continue
+				}
 				input := strings.Split(scanner.Text(), " ")
 				if len(input) != 2 {
 					continue
@@ -76,16 +194,32 @@ func main() {
 					continue
 				}
 
-				b = b.SetAt(row, col).Rotate(quad, direction)
+				if err := g.Play(core.Move{Row: row, Col: col, Quadrant: quad, Direction: direction}); err != nil {
+					fmt.Println(err)
+				}
 			} else {
 
-				move := ai.FindBestMove(b, 5, 3).Move
+				limits := ai.Limits{Time: *thinkTime, RootMoves: g.LegalMoves(), Game: &g, Table: table, Threads: *threads, Eval: eval, Book: book, Solve: *solveEmpty}
+				if *verbose {
+					limits.Progress = printProgress
+				}
+				best, _ := engine.BestMove(context.Background(), b, limits)
+				move := best.Move
 				fmt.Println("My move: ", move.Repr())
-				b = b.SetAt(move.Row, move.Col).Rotate(move.Quadrant, move.Direction)
+				if best.Depth > 0 {
+					fmt.Printf("Expecting %v after %v (depth %v, %v nodes in %v)\n",
+						best.Score, movesRepr(best.PV), best.Depth, best.Nodes, best.Elapsed.Round(time.Millisecond))
+				}
+				if err := g.Play(move); err != nil {
+					fmt.Println("Engine played an illegal move:", err)
+					os.Exit(1)
+				}
 			}
 		}
 
-		w := b.Winner()
+		fmt.Printf("\nBoard:\n%v\nGame record:\n%v\n", g.Board.Repr(), g.Record())
+
+		w := g.Board.Winner()
 		if w == core.WHITE {
 			fmt.Println("White wins")
 		} else if w == core.BLACK {
@@ -95,6 +229,113 @@ func main() {
 		}
 
 	} else {
-		view.RunUI()
+		handicap.Weaker = core.WHITE
+		view.RunUI(view.Options{SwapRule: *swapRule, Handicap: handicap, HashSize: *hashSize, ThinkTime: *thinkTime, Threads: *threads, Engine: *engineName, Eval: eval, Book: book, Solve: *solveEmpty})
+	}
+}
+
+// prove runs a proof-number search on the position after the first game
+// of the records file and prints the proof tree, if any.
+func prove(recordsPath string, moves int) error {
+	f, err := os.Open(recordsPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	games, err := core.ReadRecords(f)
+	if err != nil {
+		return err
+	}
+	if len(games) == 0 {
+		return fmt.Errorf("no game in %v", recordsPath)
+	}
+
+	b := games[0].Board
+	fmt.Printf("\nBoard:\n%v\n", b.Repr())
+	proof, err := ai.ProveWin(context.Background(), b, moves, 10000000)
+	if err != nil {
+		return err
+	}
+	if proof == nil {
+		fmt.Printf("No forced win within %v moves\n", moves)
+		return nil
+	}
+	fmt.Printf("Forced win within %v moves, proof with %v nodes:\n%v", moves, proof.Size(), proof)
+	return nil
+}
+
+// writeBook builds an opening book from the game records, if given, or by
+// searching, and saves it.
+func writeBook(path, recordsPath string, opts ai.BookOptions) error {
+	var book *ai.Book
+	if recordsPath != "" {
+		f, err := os.Open(recordsPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		games, err := core.ReadRecords(f)
+		if err != nil {
+			return err
+		}
+		book = ai.BookFromRecords(games, opts.Plies)
+	} else {
+		var err error
+		if book, err = ai.BookFromSearch(context.Background(), opts); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Writing %v positions to %v\n", book.Len(), path)
+	return book.Save(path)
+}
+
+// tune runs the given tuning method and writes the result to the
+// checkpoint file of the options.
+func tune(method, recordsPath string, start ai.EvalParams, opts ai.TuneOptions) error {
+	opts.Progress = func(iteration int, params ai.EvalParams, measure float64) {
+		fmt.Printf("iteration %v measure %.6f params %+v\n", iteration, measure, params)
+	}
+
+	switch method {
+	case "texel":
+		f, err := os.Open(recordsPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		games, err := core.ReadRecords(f)
+		if err != nil {
+			return err
+		}
+		_, err = ai.TuneTexel(games, start, opts)
+		return err
+	case "spsa":
+		_, err := ai.TuneSPSA(start, opts, ai.MatchOptions{Openings: 4, Plies: 4, Depth: 2, Seed: 1})
+		return err
+	}
+	return fmt.Errorf("unknown tuning method %q", method)
+}
+
+// printHints shows the three best moves for the player to move.
+func printHints(b core.Board, limits ai.Limits) {
+	lines, _ := ai.Analyze(context.Background(), b, limits, 3)
+	for i, l := range lines {
+		fmt.Printf("%v. %v score %v pv %v\n", i+1, l.Move.Repr(), l.Score, movesRepr(l.PV))
+	}
+}
+
+func printProgress(info ai.Info) {
+	fmt.Printf("depth %v score %v nodes %v nps %v pv %v\n", info.Depth, info.Score, info.Nodes, info.NodesPerSecond, movesRepr(info.PV))
+}
+
+func movesRepr(moves []core.Move) string {
+	reprs := make([]string, len(moves))
+	for i, m := range moves {
+		reprs[i] = m.Repr()
 	}
+	return strings.Join(reprs, ", ")
 }
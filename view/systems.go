@@ -1,6 +1,8 @@
 package view
 
 import (
+	"context"
+	"fmt"
 	"image/color"
 
 	"engo.io/ecs"
@@ -30,16 +32,35 @@ type StatusLabel struct {
 	common.SpaceComponent
 }
 
+type Button struct {
+	ecs.BasicEntity
+	common.RenderComponent
+	common.SpaceComponent
+	common.MouseComponent
+}
+
 type BoardSystem struct {
 	world         *ecs.World
+	options       Options
 	entities      []Checker
 	fields        [6][6]Field
 	checker       [6][6]Checker
 	gameState     int
 	stateLabel    StatusLabel
+	newGameButton Button
+	game          core.Game
 	boardModel    core.Board
+	humanColor    int
+	table         *ai.TranspositionTable
+	engine        ai.Engine
 	pendingMove   core.Move
 	pauseDuration float32
+
+	// State of the computer's search running in the background
+	cancelSearch   context.CancelFunc
+	searchResult   chan core.Move
+	searchProgress chan ai.Info
+	progressText   string
 }
 
 // All those const values assume a screen widht/height of 1000px ... not very dynamic
@@ -60,12 +81,13 @@ const (
 	gameWonPlayer          = iota
 	gameWonAI              = iota
 	evaluatePosition       = iota
+	computerSwapped        = iota
+	computerFailed         = iota
 )
 
 // New is the initialisation of the System
 func (bs *BoardSystem) New(w *ecs.World) {
 	bs.world = w
-	bs.boardModel = core.NewBoard()
 
 	var renderSys *common.RenderSystem
 	var mouseSys *common.MouseSystem
@@ -119,7 +141,105 @@ func (bs *BoardSystem) New(w *ecs.World) {
 		&bs.stateLabel.RenderComponent,
 		&common.SpaceComponent{Position: engo.Point{5, 5}})
 
-	bs.gameState = waitForChecker
+	fntButton := &common.Font{
+		URL:  "UbuntuMono-R.ttf",
+		FG:   color.White,
+		Size: 16,
+	}
+	err = fntButton.CreatePreloaded()
+	if err != nil {
+		panic(err)
+	}
+
+	bs.newGameButton = Button{BasicEntity: ecs.NewBasic()}
+	bs.newGameButton.RenderComponent.Drawable = common.Text{
+		Font: fntButton,
+		Text: "[New Game]",
+	}
+	bs.newGameButton.SetShader(common.HUDShader)
+	bs.newGameButton.SpaceComponent = common.SpaceComponent{
+		Position: engo.Point{X: 890, Y: 5},
+		Width:    100,
+		Height:   20,
+	}
+
+	mouseSys.Add(&bs.newGameButton.BasicEntity, &bs.newGameButton.MouseComponent, &bs.newGameButton.SpaceComponent, nil)
+	renderSys.Add(&bs.newGameButton.BasicEntity, &bs.newGameButton.RenderComponent, &bs.newGameButton.SpaceComponent)
+
+	bs.newGame()
+}
+
+// newGame abandons the current game, if any, and starts a new one
+func (bs *BoardSystem) newGame() {
+	bs.stopSearch()
+
+	bs.game = core.NewGame(bs.options.SwapRule)
+	if !bs.options.Handicap.IsZero() {
+		bs.game = core.NewHandicapGame(bs.options.Handicap)
+	}
+	bs.boardModel = bs.game.Board
+	// The player always moves first, so with the swap rule only the
+	// computer gets to swap
+	bs.humanColor = core.WHITE
+	// A search that has just been canceled may still access the old table
+	bs.table = ai.NewTranspositionTable(bs.options.HashSize)
+	engine, err := ai.NewEngine(bs.options.Engine)
+	if err != nil {
+		panic(err)
+	}
+	bs.engine = engine
+	bs.pauseDuration = 0
+	bs.gameState = evaluatePosition
+}
+
+// startSearch lets the computer search for its next move in the background
+func (bs *BoardSystem) startSearch() {
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan core.Move, 1)
+	progress := make(chan ai.Info, 64)
+
+	// The search runs on a copy of the game
+	game := bs.game
+	limits := ai.Limits{
+		Time:      bs.options.ThinkTime,
+		RootMoves: game.LegalMoves(),
+		Game:      &game,
+		Table:     bs.table,
+		Threads:   bs.options.Threads,
+		Eval:      bs.options.Eval,
+		Book:      bs.options.Book,
+		Solve:     bs.options.Solve,
+		Progress: func(info ai.Info) {
+			select {
+			case progress <- info:
+			default:
+			}
+		},
+	}
+
+	board := game.Board
+	engine := bs.engine
+	go func() {
+		best, err := engine.BestMove(ctx, board, limits)
+		if err == nil {
+			result <- best.Move
+		}
+	}()
+
+	bs.cancelSearch = cancel
+	bs.searchResult = result
+	bs.searchProgress = progress
+	bs.progressText = ""
+}
+
+// stopSearch cancels the computer's search, if it is running
+func (bs *BoardSystem) stopSearch() {
+	if bs.cancelSearch != nil {
+		bs.cancelSearch()
+	}
+	bs.cancelSearch = nil
+	bs.searchResult = nil
+	bs.searchProgress = nil
 }
 
 // Update is run every frame, with `dt` being the time
@@ -149,6 +269,10 @@ func (bs *BoardSystem) Update(dt float32) {
 		}
 	}
 
+	if bs.newGameButton.MouseComponent.Clicked {
+		bs.newGame()
+	}
+
 	// Pause, if necesssary
 	bs.pauseDuration -= dt
 	if bs.pauseDuration > 0 {
@@ -167,24 +291,49 @@ func (bs *BoardSystem) Update(dt float32) {
 							// Attempt to place checker on occupied field => ignore
 							continue
 						}
-						bs.boardModel = bs.boardModel.SetAt(i, j)
+						bs.pendingMove = core.Move{Row: i, Col: j}
+						bs.boardModel = bs.game.Board.SetAt(i, j)
 						bs.gameState = waitForRotation
 					} else {
-						quad := quadrantForIndexes(i, j)
-						bs.boardModel = bs.boardModel.Rotate(quad, core.COUNTERCLOCKWISE)
-						bs.gameState = evaluatePosition
+						bs.playerRotates(quadrantForIndexes(i, j), core.COUNTERCLOCKWISE)
 					}
 				} else if field.MouseComponent.RightClicked && bs.gameState == waitForRotation {
-					quad := quadrantForIndexes(i, j)
-					bs.boardModel = bs.boardModel.Rotate(quad, core.CLOCKWISE)
-					bs.gameState = evaluatePosition
+					bs.playerRotates(quadrantForIndexes(i, j), core.CLOCKWISE)
 				}
 			}
 		}
 	} else if bs.gameState == computerThinking {
 
-		bs.pendingMove = ai.FindBestMove(bs.boardModel, 2, 2).Move
-		bs.gameState = computerSettingChecker
+		if bs.searchResult == nil {
+			if bs.game.CanSwap() && ai.ShouldSwap(bs.game.Board, bs.options.Eval) {
+				bs.game.Swap()
+				bs.humanColor = -bs.humanColor
+				bs.gameState = computerSwapped
+				bs.pauseDuration = 2.0
+			} else {
+				bs.startSearch()
+			}
+		} else {
+			for polling := true; polling; {
+				select {
+				case info := <-bs.searchProgress:
+					bs.progressText = fmt.Sprintf("(depth %v, %v nodes)", info.Depth, info.Nodes)
+				default:
+					polling = false
+				}
+			}
+
+			select {
+			case move := <-bs.searchResult:
+				bs.stopSearch()
+				bs.pendingMove = move
+				bs.gameState = computerSettingChecker
+			default:
+			}
+		}
+
+	} else if bs.gameState == computerSwapped {
+		bs.gameState = evaluatePosition
 
 	} else if bs.gameState == computerSettingChecker {
 
@@ -193,19 +342,26 @@ func (bs *BoardSystem) Update(dt float32) {
 		bs.pauseDuration = 0.5
 
 	} else if bs.gameState == computerRotating {
-		bs.boardModel = bs.boardModel.Rotate(bs.pendingMove.Quadrant, bs.pendingMove.Direction)
-		bs.gameState = evaluatePosition
+		err := bs.game.Play(bs.pendingMove)
+		bs.boardModel = bs.game.Board
+		if err != nil {
+			// The game cannot go on => wait for a new one
+			fmt.Println("Engine played an illegal move:", err)
+			bs.gameState = computerFailed
+		} else {
+			bs.gameState = evaluatePosition
+		}
 
 	} else if bs.gameState == evaluatePosition {
 		winner := bs.boardModel.Winner()
-		if winner == core.WHITE {
+		if winner == bs.humanColor {
 			bs.gameState = gameWonPlayer
-		} else if winner == core.BLACK {
+		} else if winner == -bs.humanColor {
 			bs.gameState = gameWonAI
-		} else if winner == core.DRAW {
+		} else if winner == core.DRAW || core.IsDeadDraw(bs.boardModel) {
 			bs.gameState = gameDrawn
 		} else {
-			if bs.boardModel.Turn == core.WHITE {
+			if bs.boardModel.Turn == bs.humanColor {
 				bs.gameState = waitForChecker
 			} else {
 				bs.gameState = computerThinking
@@ -225,10 +381,26 @@ func (bs *BoardSystem) Update(dt float32) {
 		panic(err)
 	}
 
+	text := textForGameState(bs.gameState)
+	if bs.gameState == computerThinking {
+		text += " " + bs.progressText
+	}
 	bs.stateLabel.RenderComponent.Drawable = common.Text{
 		Font: fntWhite,
-		Text: textForGameState(bs.gameState),
+		Text: text,
+	}
+}
+
+// playerRotates completes the player's pending move with the given rotation
+func (bs *BoardSystem) playerRotates(quadrant, direction int) {
+	bs.pendingMove.Quadrant = quadrant
+	bs.pendingMove.Direction = direction
+	if bs.game.Play(bs.pendingMove) != nil {
+		// Rotation not allowed, e.g. due to a handicap => wait for another one
+		return
 	}
+	bs.boardModel = bs.game.Board
+	bs.gameState = evaluatePosition
 }
 
 func quadrantForIndexes(i, j int) int {
@@ -260,6 +432,10 @@ func textForGameState(state int) string {
 		return "You win - congratulations!"
 	case gameWonAI:
 		return "I win - better luck next time!"
+	case computerSwapped:
+		return "I swap - your opening is mine now, you continue with the other color"
+	case computerFailed:
+		return "I played an illegal move - please start a new game"
 	default:
 		return ""
 	}
@@ -2,13 +2,32 @@ package view
 
 import (
 	"image/color"
+	"time"
 
 	"engo.io/ecs"
 	"engo.io/engo"
 	"engo.io/engo/common"
+	"github.com/jcharra/penta-go/ai"
+	"github.com/jcharra/penta-go/core"
 )
 
-type pentagoScene struct{}
+// Options configure the game played in the UI
+type Options struct {
+	SwapRule  bool           // allow the computer to swap after the player's first move
+	Handicap  core.Handicap  // handicap in favor of the player, who plays white
+	HashSize  int            // size of the computer's transposition table in MB
+	ThinkTime time.Duration  // time the computer thinks about each move
+	Threads   int            // number of goroutines of the computer's search
+	Engine    string         // name of the computer's engine, see ai.NewEngine
+	Eval      *ai.EvalParams // weights of the computer's evaluation, nil for the defaults
+	Book      *ai.Book       // opening book of the computer, if any
+	Solve     int            // number of empty fields from which on the computer solves the game exactly
+}
+
+type pentagoScene struct {
+	options Options
+	board   *BoardSystem
+}
 
 // Type uniquely defines your game type
 func (*pentagoScene) Type() string {
@@ -24,20 +43,30 @@ func (*pentagoScene) Preload() {
 }
 
 // Setup is called before the main loop starts. It allows you to add entities and systems to your Scene.
-func (*pentagoScene) Setup(world *ecs.World) {
+func (scene *pentagoScene) Setup(world *ecs.World) {
 	common.SetBackground(color.Black)
 
 	world.AddSystem(&common.RenderSystem{})
 	world.AddSystem(&common.MouseSystem{})
-	world.AddSystem(&BoardSystem{})
+	scene.board = &BoardSystem{options: scene.options}
+	world.AddSystem(scene.board)
+}
+
+// Exit is called when the window is closed. It stops the computer's search
+// before quitting.
+func (scene *pentagoScene) Exit() {
+	if scene.board != nil {
+		scene.board.stopSearch()
+	}
+	engo.Exit()
 }
 
-func RunUI() {
+func RunUI(options Options) {
 	opts := engo.RunOptions{
 		Title:  "Pentago",
 		Width:  1000,
 		Height: 800,
 	}
 
-	engo.Run(opts, &pentagoScene{})
+	engo.Run(opts, &pentagoScene{options: options})
 }